@@ -1,12 +1,23 @@
 package api
 
 import (
+	"bytes"
 	"citation-scanner/internal/cache"
+	"citation-scanner/internal/export"
 	"citation-scanner/internal/parser"
+	"citation-scanner/pkg/bibliography"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 )
 
+// maxScanDepth is the deepest recursive scan a client may request, as any client can start one.
+const maxScanDepth = 3
+
+// scanBudget limits the pages, cost and time of every recursive scan started through the API.
+var scanBudget = parser.Budget{MaxPages: 50, MaxCost: 1.00, MaxDuration: 5 * time.Minute}
+
 // Home handler just for the base route
 func homeHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -37,6 +48,16 @@ func parsePageHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Only return the claims of the requested types, if any
+	types, err := parser.ParseClaimTypes(r.URL.Query().Get("type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Respond with the bibliography of the sources instead when the client asks for a citation format
+	format := export.NegotiateCitationFormat(r.Header.Get("Accept"))
+
 	// Check cache for the URL
 	cachedResponse, found, err := cache.GetCachedResponse(requestBody.URL)
 	if err != nil {
@@ -44,13 +65,28 @@ func parsePageHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if found {
+	if found && len(types) == 0 && format == "" {
 		// Cached response found, return it
 		w.Header().Set("Content-Type", "application/json")
 		w.Write([]byte(cachedResponse))
 		return
 	}
 
+	if found {
+		// Cached response found, filter it by type
+		var parsedClaims parser.ParsedClaims
+		if err := json.Unmarshal([]byte(cachedResponse), &parsedClaims); err != nil {
+			http.Error(w, "Failed to decode cached response", http.StatusInternalServerError)
+			return
+		}
+		if format != "" {
+			writeCitations(w, export.Citations(parser.FilterClaims(&parsedClaims, types...)), format)
+			return
+		}
+		writeJSON(w, parser.FilterClaims(&parsedClaims, types...))
+		return
+	}
+
 	// No cached response or it's expired, call the ParsePageClaims function
 	parsedClaims, err := parser.ParsePageClaims(requestBody.URL)
 	if err != nil {
@@ -72,7 +108,202 @@ func parsePageHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Respond with the parsed claims in JSON format
+	// Respond with the parsed claims of the requested types in JSON format, or with their sources in a citation format
+	if format != "" {
+		writeCitations(w, export.Citations(parser.FilterClaims(parsedClaims, types...)), format)
+		return
+	}
+	if len(types) > 0 {
+		writeJSON(w, parser.FilterClaims(parsedClaims, types...))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(responseData)
+}
+
+// writeJSON encodes v as the JSON response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	responseData, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(responseData)
 }
+
+// writeCitations writes bibliographic records as the response in the given citation format.
+func writeCitations(w http.ResponseWriter, items []bibliography.Item, format string) {
+	var buf bytes.Buffer
+	if err := export.WriteCitations(&buf, items, format); err != nil {
+		http.Error(w, "Failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", export.CitationContentType(format))
+	w.Write(buf.Bytes())
+}
+
+// graphHandler recursively scans a page and responds with its citation graph in DOT, GraphML or JSON Graph format.
+// Setting "verify" checks every claim against its sources, adding the verdicts to the graph.
+func graphHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse the incoming JSON payload
+	var requestBody struct {
+		URL    string `json:"url"`
+		Depth  int    `json:"depth"`
+		Format string `json:"format"`
+		Verify bool   `json:"verify"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	// Check if URL is provided
+	if requestBody.URL == "" {
+		http.Error(w, "URL is required", http.StatusBadRequest)
+		return
+	}
+	if requestBody.Format == "" {
+		requestBody.Format = export.FormatJSONGraph
+	}
+	if err := export.CheckGraphFormat(requestBody.Format); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if requestBody.Depth <= 0 {
+		requestBody.Depth = 1
+	}
+	if requestBody.Depth > maxScanDepth {
+		http.Error(w, fmt.Sprintf("depth must be at most %d", maxScanDepth), http.StatusBadRequest)
+		return
+	}
+
+	// Only graph the claims of the requested types, if any
+	types, err := parser.ParseClaimTypes(r.URL.Query().Get("type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts := []func(*parser.Options){parser.WithBudget(scanBudget)}
+	if requestBody.Verify {
+		opts = append(opts, parser.WithVerification())
+	}
+	aggregatedClaims, err := parser.ParseAndAggregateClaims(requestBody.URL, requestBody.Depth, opts...)
+	if err != nil {
+		http.Error(w, "Failed to parse page: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Render the graph before writing so that an encoding failure can still be reported
+	var buf bytes.Buffer
+	if err := export.WriteGraph(&buf, export.BuildGraph(parser.FilterAggregatedClaims(aggregatedClaims, types...)), requestBody.Format); err != nil {
+		http.Error(w, "Failed to encode response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", export.GraphContentType(requestBody.Format))
+	w.Write(buf.Bytes())
+}
+
+// bibliographyHandler recursively scans a page and responds with the bibliography of every source it found,
+// in CSL-JSON, BibTeX or RIS as negotiated by the Accept header.
+func bibliographyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse the incoming JSON payload
+	var requestBody struct {
+		URL   string `json:"url"`
+		Depth int    `json:"depth"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	// Check if URL is provided
+	if requestBody.URL == "" {
+		http.Error(w, "URL is required", http.StatusBadRequest)
+		return
+	}
+	if requestBody.Depth <= 0 {
+		requestBody.Depth = 1
+	}
+	if requestBody.Depth > maxScanDepth {
+		http.Error(w, fmt.Sprintf("depth must be at most %d", maxScanDepth), http.StatusBadRequest)
+		return
+	}
+	format := export.NegotiateCitationFormat(r.Header.Get("Accept"))
+	if format == "" {
+		format = export.FormatCSLJSON
+	}
+
+	// Only list the sources of the claims of the requested types, if any
+	types, err := parser.ParseClaimTypes(r.URL.Query().Get("type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	aggregatedClaims, err := parser.ParseAndAggregateClaims(requestBody.URL, requestBody.Depth, parser.WithBudget(scanBudget))
+	if err != nil {
+		http.Error(w, "Failed to parse page: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeCitations(w, export.AggregatedCitations(parser.FilterAggregatedClaims(aggregatedClaims, types...)), format)
+}
+
+// auditHandler checks the citations of a page for dead links without using the LLM. The sources of a
+// previously parsed page are audited from the cache; other pages have the external links of their body audited.
+func auditHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse the incoming JSON payload
+	var requestBody struct {
+		URL string `json:"url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	// Check if URL is provided
+	if requestBody.URL == "" {
+		http.Error(w, "URL is required", http.StatusBadRequest)
+		return
+	}
+
+	// Audit the sources of the cached claims when the page was already parsed
+	cachedResponse, found, err := cache.GetCachedResponse(requestBody.URL)
+	if err != nil {
+		http.Error(w, "Error checking cache: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if found {
+		var parsedClaims parser.ParsedClaims
+		if err := json.Unmarshal([]byte(cachedResponse), &parsedClaims); err != nil {
+			http.Error(w, "Failed to decode cached response", http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, parser.AuditClaims(&parsedClaims))
+		return
+	}
+
+	audit, err := parser.AuditPage(requestBody.URL)
+	if err != nil {
+		http.Error(w, "Failed to audit page: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, audit)
+}
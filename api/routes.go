@@ -8,4 +8,7 @@ import (
 func routes(r chi.Router) {
 	r.Get("/", homeHandler)
 	r.Post("/parse", parsePageHandler)
+	r.Post("/graph", graphHandler)
+	r.Post("/bibliography", bibliographyHandler)
+	r.Post("/audit", auditHandler)
 }
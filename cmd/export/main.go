@@ -0,0 +1,73 @@
+package main
+
+import (
+	"citation-scanner/internal/cache"
+	"citation-scanner/internal/export"
+	"citation-scanner/internal/parser"
+	"citation-scanner/pkg/bibliography"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Exports the sources of a page, or of a recursive scan, as CSL-JSON, BibTeX or RIS for reference managers.
+//
+//	go run ./cmd/export -url "https://en.wikipedia.org/wiki/Trust_(social_science)" -format ris > sources.ris
+func main() {
+	url := flag.String("url", "", "URL of the page whose sources to export")
+	depth := flag.Int("depth", 0, "depth of the recursive scan; 0 exports the sources of the page alone")
+	format := flag.String("format", export.FormatBibTeX, "output format: bibtex, ris or csl-json")
+	output := flag.String("o", "", "file to write to instead of the standard output")
+	flag.Parse()
+
+	if *url == "" {
+		fmt.Fprintln(os.Stderr, "The -url flag is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	// Check the format before paying for the scan
+	if err := export.CheckCitationFormat(*format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Initialize the cache database
+	if err := cache.InitializeCache(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize cache: %v\n", err)
+		os.Exit(1)
+	}
+	defer cache.CloseCache()
+
+	var items []bibliography.Item
+	if *depth > 0 {
+		aggregatedClaims, err := parser.ParseAndAggregateClaims(*url, *depth)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to parse page: %v\n", err)
+			os.Exit(1)
+		}
+		items = export.AggregatedCitations(aggregatedClaims)
+	} else {
+		parsedClaims, err := parser.ParsePageClaims(*url)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to parse page: %v\n", err)
+			os.Exit(1)
+		}
+		items = export.Citations(parsedClaims)
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", *output, err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := export.WriteCitations(out, items, *format); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to export sources: %v\n", err)
+		os.Exit(1)
+	}
+}
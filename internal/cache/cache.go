@@ -12,11 +12,26 @@ var db *sql.DB
 
 const CacheTTL = 24 * time.Hour
 
+// Version is part of every cache key. It is bumped whenever the format of cached responses changes, such as
+// when claims gained their type or pages their metadata, so that responses cached by older versions are parsed
+// again instead of read back.
+const Version = 3
+
+// cacheKey returns the key a response for the URL is cached under.
+func cacheKey(url string) string {
+	return fmt.Sprintf("v%d:%s", Version, url)
+}
+
 // InitializeCache sets up the SQLite database and creates the cache table if it doesn't exist.
 func InitializeCache() error {
-	// Connect to SQLite database (creates cache.db file if it doesn't exist)
+	return InitializeCacheAt("./cache.db")
+}
+
+// InitializeCacheAt sets up the SQLite database at the given path and creates the cache table if it doesn't exist.
+func InitializeCacheAt(path string) error {
+	// Connect to SQLite database (creates the file if it doesn't exist)
 	var err error
-	db, err = sql.Open("sqlite3", "./cache.db")
+	db, err = sql.Open("sqlite3", path)
 	if err != nil {
 		return fmt.Errorf("failed to connect to SQLite: %v", err)
 	}
@@ -52,11 +67,11 @@ func isExpired(timestamp string) (bool, error) {
 	return time.Since(parsedTimestamp) > CacheTTL, nil
 }
 
-// getCachedResponse retrieves a cached response and checks if it is expired.
-func getCachedResponse(url string) (string, bool, error) {
+// GetCachedResponse retrieves a cached response and checks if it is expired.
+func GetCachedResponse(url string) (string, bool, error) {
 	var response string
 	var timestamp string
-	err := db.QueryRow("SELECT response, timestamp FROM cache WHERE url = ?", url).Scan(&response, &timestamp)
+	err := db.QueryRow("SELECT response, timestamp FROM cache WHERE url = ?", cacheKey(url)).Scan(&response, &timestamp)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			// No cache entry for this URL
@@ -79,11 +94,11 @@ func getCachedResponse(url string) (string, bool, error) {
 	return response, true, nil
 }
 
-// cacheResponse stores a new response for a given URL in the cache, updating the timestamp.
-func cacheResponse(url, response string) error {
+// CacheResponse stores a new response for a given URL in the cache, updating the timestamp.
+func CacheResponse(url, response string) error {
 	_, err := db.Exec(
 		"INSERT OR REPLACE INTO cache (url, response, timestamp) VALUES (?, ?, ?)",
-		url, response, time.Now().Format(time.RFC3339),
+		cacheKey(url), response, time.Now().Format(time.RFC3339),
 	)
 	if err != nil {
 		return fmt.Errorf("error caching response for URL %s: %v", url, err)
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -113,3 +114,19 @@ func TestCacheUpdate(t *testing.T) {
 		t.Errorf("Expected cached response '%s', got '%s'", updatedResponse, cachedResponse)
 	}
 }
+
+// TestCacheKeyVersion verifies that responses cached under an older version are not read back.
+func TestCacheKeyVersion(t *testing.T) {
+	if err := InitializeCacheAt(filepath.Join(t.TempDir(), "cache.db")); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+	defer CloseCache()
+
+	url := "https://example.com/old"
+	if _, err := db.Exec("INSERT INTO cache (url, response, timestamp) VALUES (?, ?, ?)", url, "{}", time.Now().Format(time.RFC3339)); err != nil {
+		t.Fatalf("Failed to insert an unversioned entry: %v", err)
+	}
+	if _, found, err := GetCachedResponse(url); err != nil || found {
+		t.Errorf("Expected the unversioned entry to be ignored, got found %v and error %v", found, err)
+	}
+}
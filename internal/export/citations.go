@@ -0,0 +1,174 @@
+package export
+
+import (
+	"citation-scanner/internal/parser"
+	"citation-scanner/pkg/bibliography"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"time"
+)
+
+// Citation formats understood by WriteCitations.
+const (
+	FormatCSLJSON = "csl-json"
+	FormatBibTeX  = "bibtex"
+	FormatRIS     = "ris"
+)
+
+// citationMediaTypes maps the media types of the citation formats, including common aliases, to the format.
+var citationMediaTypes = map[string]string{
+	"application/vnd.citationstyles.csl+json": FormatCSLJSON,
+	"application/x-bibtex":                    FormatBibTeX,
+	"text/x-bibtex":                           FormatBibTeX,
+	"application/x-research-info-systems":     FormatRIS,
+	"application/x-ris":                       FormatRIS,
+}
+
+// Citations returns the bibliographic records of the sources cited by the claims of a page, in the order they are
+// first cited. Sources without a record from the page's reference list get a minimal web page record.
+func Citations(parsedClaims *parser.ParsedClaims) []bibliography.Item {
+	c := newCitationSet(nil)
+	c.addPage(parsedClaims)
+	return c.items
+}
+
+// AggregatedCitations returns the bibliographic records of the sources cited throughout an aggregation, each
+// listed once. Sources that were scanned are dated by the publication date found on their page.
+func AggregatedCitations(aggregatedClaims *parser.AggregatedClaims) []bibliography.Item {
+	published := make(map[string]*time.Time)
+	for _, page := range aggregatedClaims.AllClaims {
+		if page.Published != nil {
+			published[page.Page] = page.Published
+		}
+	}
+	c := newCitationSet(published)
+	for i := range aggregatedClaims.AllClaims {
+		c.addPage(&aggregatedClaims.AllClaims[i])
+	}
+	return c.items
+}
+
+// citationSet collects the records of sources without duplicates.
+type citationSet struct {
+	items     []bibliography.Item
+	seen      map[string]bool
+	published map[string]*time.Time
+}
+
+func newCitationSet(published map[string]*time.Time) *citationSet {
+	return &citationSet{seen: make(map[string]bool), published: published}
+}
+
+// addPage adds the sources of every claim of a page, with their records when the page's reference list had them.
+func (c *citationSet) addPage(page *parser.ParsedClaims) {
+	for _, claim := range page.Claims {
+		for _, source := range claim.Source {
+			c.add(page.Page, source, recordOf(claim, source))
+		}
+		// Sources without a URL only have a record
+		for _, record := range claim.Details {
+			if record.URL == "" && record.Bibliography != nil {
+				c.add(page.Page, "", &record)
+			}
+		}
+	}
+}
+
+// add adds a source of a page unless it was already added, keyed by its URL. Sources without a URL are keyed by
+// their DOI or ISBN, or else by the ID of their record, which is only unique within the citing page.
+func (c *citationSet) add(page, url string, record *parser.SourceRecord) {
+	var item bibliography.Item
+	if record != nil && record.Bibliography != nil {
+		item = *record.Bibliography
+	} else {
+		item = bibliography.Item{Type: bibliography.TypeWebpage, Title: url}
+	}
+	if url != "" {
+		item.URL = url
+	}
+
+	key := url
+	switch {
+	case key != "":
+	case item.DOI != "":
+		key = "doi:" + item.DOI
+	case item.ISBN != "":
+		key = "isbn:" + item.ISBN
+	default:
+		key = page + "#" + item.ID
+	}
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+
+	if item.Issued == nil && c.published[url] != nil {
+		item.Issued = bibliography.NewDate(*c.published[url])
+	}
+	if item.Accessed == nil && record != nil && record.AccessDate != nil {
+		item.Accessed = bibliography.NewDate(*record.AccessDate)
+	}
+	item.ID = fmt.Sprintf("source-%d", len(c.items)+1)
+	c.items = append(c.items, item)
+}
+
+// recordOf returns the record of a source of the claim, if it has one.
+func recordOf(claim parser.Claim, source string) *parser.SourceRecord {
+	for i := range claim.Details {
+		if claim.Details[i].URL == source {
+			return &claim.Details[i]
+		}
+	}
+	return nil
+}
+
+// CheckCitationFormat returns an error if the format is not one WriteCitations understands, so that callers can
+// reject it before doing the work of a scan.
+func CheckCitationFormat(format string) error {
+	switch format {
+	case FormatCSLJSON, FormatBibTeX, FormatRIS:
+		return nil
+	}
+	return fmt.Errorf("unknown citation format %q", format)
+}
+
+// WriteCitations writes bibliographic records in the given format.
+func WriteCitations(w io.Writer, items []bibliography.Item, format string) error {
+	switch format {
+	case FormatCSLJSON:
+		return WriteCSLJSON(w, items)
+	case FormatBibTeX:
+		return WriteBibTeX(w, items)
+	case FormatRIS:
+		return WriteRIS(w, items)
+	}
+	return CheckCitationFormat(format)
+}
+
+// CitationContentType returns the media type of a citation format.
+func CitationContentType(format string) string {
+	switch format {
+	case FormatBibTeX:
+		return "application/x-bibtex"
+	case FormatRIS:
+		return "application/x-research-info-systems"
+	}
+	return "application/vnd.citationstyles.csl+json"
+}
+
+// NegotiateCitationFormat returns the citation format requested by an Accept header, or "" when it accepts none of them.
+// The media types are considered in the order they are listed.
+func NegotiateCitationFormat(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if format, ok := citationMediaTypes[mediaType]; ok {
+			return format
+		}
+	}
+	return ""
+}
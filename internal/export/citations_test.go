@@ -0,0 +1,216 @@
+package export
+
+import (
+	"bytes"
+	"citation-scanner/internal/parser"
+	"citation-scanner/pkg/bibliography"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCitedPage returns a page citing a journal article, a web page without a record and a book without a URL.
+func testCitedPage() *parser.ParsedClaims {
+	accessed := time.Date(2020, 3, 13, 0, 0, 0, 0, time.UTC)
+	return &parser.ParsedClaims{
+		Page: "https://example.org/root",
+		Claims: []parser.Claim{
+			{
+				Claim:  "Trust lowers transaction costs.[1]",
+				Source: []string{"https://doi.org/10.1000/xyz123", "https://example.net/unrecorded"},
+				Details: []parser.SourceRecord{{
+					URL:        "https://doi.org/10.1000/xyz123",
+					AccessDate: &accessed,
+					Bibliography: &bibliography.Item{
+						ID:             "cite_note-1",
+						Type:           bibliography.TypeArticleJournal,
+						Title:          "Trust & growth",
+						Author:         []bibliography.Name{{Family: "Doe", Given: "Jane"}, {Family: "Roe", Given: "Richard"}},
+						ContainerTitle: "Journal of Economics",
+						Issued:         &bibliography.Date{DateParts: [][]int{{2019}}},
+						Volume:         "12",
+						Issue:          "3",
+						Page:           "45–67",
+						DOI:            "10.1000/xyz123",
+					},
+				}},
+			},
+			{
+				Claim:  "Trust is a form of social capital.[2]",
+				Source: []string{},
+				Details: []parser.SourceRecord{{Bibliography: &bibliography.Item{
+					ID:             "cite_note-2",
+					Type:           bibliography.TypeBook,
+					Title:          "Bowling Alone",
+					Author:         []bibliography.Name{{Family: "Putnam", Given: "Robert D."}},
+					Publisher:      "Simon & Schuster",
+					PublisherPlace: "New York",
+					Issued:         &bibliography.Date{DateParts: [][]int{{2000}}},
+					ISBN:           "9780684832838",
+				}}},
+			},
+			{
+				Claim:  "Trust lowers transaction costs again.[1]",
+				Source: []string{"https://doi.org/10.1000/xyz123"},
+			},
+		},
+	}
+}
+
+// TestCitations verifies that every source is listed once, with its record or a minimal web page record.
+func TestCitations(t *testing.T) {
+	items := Citations(testCitedPage())
+	if len(items) != 3 {
+		t.Fatalf("Expected 3 citations, got %+v", items)
+	}
+	if items[0].DOI != "10.1000/xyz123" || items[0].URL != "https://doi.org/10.1000/xyz123" || isoDate(items[0].Accessed) != "2020-03-13" {
+		t.Errorf("Expected the journal article with its URL and access date, got %+v", items[0])
+	}
+	if items[1].Type != bibliography.TypeWebpage || items[1].URL != "https://example.net/unrecorded" {
+		t.Errorf("Expected a web page record for the source without one, got %+v", items[1])
+	}
+	if items[2].ISBN != "9780684832838" || items[2].URL != "" {
+		t.Errorf("Expected the book without a URL, got %+v", items[2])
+	}
+}
+
+// TestAggregatedCitations verifies that sources cited by several pages are listed once and dated by their scanned page.
+func TestAggregatedCitations(t *testing.T) {
+	published := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	aggregatedClaims := testAggregation()
+	aggregatedClaims.AllClaims[1].Published = &published
+	aggregatedClaims.AllClaims[1].Claims[0].Source = []string{"https://example.net/unscanned"}
+
+	items := AggregatedCitations(aggregatedClaims)
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 citations, got %+v", items)
+	}
+	if isoDate(items[0].Issued) != "2021-02-01" {
+		t.Errorf("Expected the scanned source to be dated 2021-02-01, got %+v", items[0].Issued)
+	}
+}
+
+// TestWriteBibTeX verifies the entry types, keys, escaping and fields of BibTeX output.
+func TestWriteBibTeX(t *testing.T) {
+	page := testCitedPage()
+	items := append(Citations(page), *page.Claims[0].Details[0].Bibliography)
+
+	var buf bytes.Buffer
+	if err := WriteBibTeX(&buf, items); err != nil {
+		t.Fatalf("Error writing BibTeX: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"@article{doe2019,\n",
+		"  author = {Doe, Jane and Roe, Richard},\n",
+		`  title = {Trust \& growth},` + "\n",
+		"  journal = {Journal of Economics},\n",
+		"  pages = {45--67},\n",
+		"  urldate = {2020-03-13},\n",
+		"@misc{source,\n",
+		"@book{putnam2000,\n",
+		"  address = {New York},\n",
+		"@article{doe2019a,\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected BibTeX to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "@") != 4 || strings.Count(out, "{") != strings.Count(out, "}") {
+		t.Errorf("Expected 4 balanced entries, got:\n%s", out)
+	}
+}
+
+// TestWriteRIS verifies the reference types and tags of RIS output.
+func TestWriteRIS(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteRIS(&buf, Citations(testCitedPage())); err != nil {
+		t.Fatalf("Error writing RIS: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"TY  - JOUR\r\nAU  - Doe, Jane\r\nAU  - Roe, Richard\r\nTI  - Trust & growth\r\nT2  - Journal of Economics\r\n",
+		"PY  - 2019\r\nVL  - 12\r\nIS  - 3\r\nSP  - 45\r\nEP  - 67\r\nDO  - 10.1000/xyz123\r\n",
+		"Y2  - 2020/03/13/\r\n",
+		"TY  - ELEC\r\n",
+		"TY  - BOOK\r\n",
+		"SN  - 9780684832838\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected RIS to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "ER  - \r\n") != 3 {
+		t.Errorf("Expected 3 records, got:\n%s", out)
+	}
+}
+
+// TestWriteCitationsCSLJSON verifies that CSL-JSON output is an array of items.
+func TestWriteCitationsCSLJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCitations(&buf, nil, FormatCSLJSON); err != nil {
+		t.Fatalf("Error writing CSL-JSON: %v", err)
+	}
+	var items []bibliography.Item
+	if err := json.Unmarshal(buf.Bytes(), &items); err != nil || items == nil {
+		t.Errorf("Expected an empty array, got %q (%v)", buf.String(), err)
+	}
+	if err := WriteCitations(&buf, nil, "endnote"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+// TestNegotiateCitationFormat verifies that the first citation format of an Accept header is selected.
+func TestNegotiateCitationFormat(t *testing.T) {
+	tests := map[string]string{
+		"application/x-bibtex":                                   FormatBibTeX,
+		"text/html, application/x-research-info-systems;q=0.9":   FormatRIS,
+		"application/vnd.citationstyles.csl+json, text/x-bibtex": FormatCSLJSON,
+		"application/json":                                       "",
+		"":                                                       "",
+	}
+	for accept, want := range tests {
+		if got := NegotiateCitationFormat(accept); got != want {
+			t.Errorf("NegotiateCitationFormat(%q) = %q, want %q", accept, got, want)
+		}
+	}
+}
+
+// TestAggregatedCitationsPageLocalIDs verifies that different books without a URL that share a reference ID on different pages are both listed.
+func TestAggregatedCitationsPageLocalIDs(t *testing.T) {
+	book := func(title string) []parser.SourceRecord {
+		return []parser.SourceRecord{{Bibliography: &bibliography.Item{ID: "cite_note-4", Type: bibliography.TypeBook, Title: title}}}
+	}
+	aggregatedClaims := &parser.AggregatedClaims{AllClaims: []parser.ParsedClaims{
+		{Page: "https://example.org/a", Claims: []parser.Claim{{Claim: "A.[4]", Source: []string{}, Details: book("First Book")}}},
+		{Page: "https://example.org/b", Claims: []parser.Claim{{Claim: "B.[4]", Source: []string{}, Details: book("Second Book")}}},
+	}}
+
+	items := AggregatedCitations(aggregatedClaims)
+	if len(items) != 2 || items[0].Title != "First Book" || items[1].Title != "Second Book" {
+		t.Errorf("Expected both books, got %+v", items)
+	}
+}
+
+// TestBibTeXKeys verifies that keys stay valid and unique past 26 duplicates and do not collide with other base keys.
+func TestBibTeXKeys(t *testing.T) {
+	keys := make(map[string]bool)
+	var items []bibliography.Item
+	for i := 0; i < 30; i++ {
+		items = append(items, bibliography.Item{Type: bibliography.TypeWebpage})
+	}
+	items = append(items, bibliography.Item{Type: bibliography.TypeBook, Author: []bibliography.Name{{Family: "Sourcea"}}})
+
+	for i, item := range items {
+		key := bibTeXKey(item, keys)
+		for _, r := range key {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+				t.Fatalf("Expected key %d to be alphanumeric, got %q", i, key)
+			}
+		}
+	}
+	if len(keys) != len(items) || !keys["sourceaa"] || !keys["sourced"] {
+		t.Errorf("Expected %d unique keys including sourceaa and sourced, got %v", len(items), keys)
+	}
+}
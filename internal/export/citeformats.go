@@ -0,0 +1,237 @@
+package export
+
+import (
+	"citation-scanner/pkg/bibliography"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// WriteCSLJSON writes bibliographic records as a CSL-JSON array.
+func WriteCSLJSON(w io.Writer, items []bibliography.Item) error {
+	if items == nil {
+		items = []bibliography.Item{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(items); err != nil {
+		return fmt.Errorf("failed to encode CSL-JSON: %v", err)
+	}
+	return nil
+}
+
+// bibTeXTypes maps CSL item types to BibTeX entry types.
+var bibTeXTypes = map[string]string{
+	bibliography.TypeArticleJournal:   "article",
+	bibliography.TypeArticleNewspaper: "article",
+	bibliography.TypeBook:             "book",
+}
+
+// bibTeXSpecial matches the characters that must be escaped in BibTeX field values.
+var bibTeXSpecial = regexp.MustCompile(`[\\{}&%$#_]`)
+
+// WriteBibTeX writes bibliographic records as BibTeX entries, keyed by the first author's family name and the year.
+func WriteBibTeX(w io.Writer, items []bibliography.Item) error {
+	var sb strings.Builder
+	keys := make(map[string]bool)
+	for i, item := range items {
+		entryType, ok := bibTeXTypes[item.Type]
+		if !ok {
+			entryType = "misc"
+		}
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "@%s{%s,\n", entryType, bibTeXKey(item, keys))
+
+		field := func(name, value string) {
+			if value != "" {
+				fmt.Fprintf(&sb, "  %s = {%s},\n", name, value)
+			}
+		}
+		var authors []string
+		for _, name := range item.Author {
+			authors = append(authors, bibTeXName(name))
+		}
+		field("author", strings.Join(authors, " and "))
+		field("title", bibTeXEscape(item.Title))
+		if entryType == "article" {
+			field("journal", bibTeXEscape(item.ContainerTitle))
+		} else {
+			field("howpublished", bibTeXEscape(item.ContainerTitle))
+		}
+		field("publisher", bibTeXEscape(item.Publisher))
+		field("address", bibTeXEscape(item.PublisherPlace))
+		if year := item.Issued.Year(); year != 0 {
+			field("year", fmt.Sprint(year))
+		}
+		field("volume", item.Volume)
+		field("number", item.Issue)
+		field("pages", strings.ReplaceAll(item.Page, "–", "--"))
+		field("doi", item.DOI)
+		field("isbn", item.ISBN)
+		field("pmid", item.PMID)
+		field("url", item.URL)
+		field("urldate", isoDate(item.Accessed))
+		sb.WriteString("}\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// bibTeXKey returns a unique citation key for an item, adding letters ("a" to "z", then "aa", "ab" and so on)
+// to keys that were already used.
+func bibTeXKey(item bibliography.Item, keys map[string]bool) string {
+	base := "source"
+	if len(item.Author) > 0 {
+		base = item.Author[0].Family
+		if base == "" {
+			base = item.Author[0].Literal
+		}
+	}
+	var sb strings.Builder
+	for _, r := range strings.ToLower(base) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			sb.WriteRune(r)
+		}
+	}
+	if sb.Len() == 0 {
+		sb.WriteString("source")
+	}
+	if year := item.Issued.Year(); year != 0 {
+		fmt.Fprint(&sb, year)
+	}
+
+	base = sb.String()
+	key := base
+	for n := 1; keys[key]; n++ {
+		// A suffixed key may also be another item's base key, so keep going until an unused one is found
+		key = base + keySuffix(n)
+	}
+	keys[key] = true
+	return key
+}
+
+// keySuffix returns the nth letter suffix of a citation key: "a" to "z", then "aa" to "zz" and so on.
+func keySuffix(n int) string {
+	var suffix []byte
+	for ; n > 0; n = (n - 1) / 26 {
+		suffix = append([]byte{byte('a' + (n-1)%26)}, suffix...)
+	}
+	return string(suffix)
+}
+
+// bibTeXName writes a name as "Family, Given", bracing literal names so that BibTeX does not split them.
+func bibTeXName(name bibliography.Name) string {
+	switch {
+	case name.Literal != "":
+		return "{" + bibTeXEscape(name.Literal) + "}"
+	case name.Given == "":
+		return bibTeXEscape(name.Family)
+	}
+	return bibTeXEscape(name.Family) + ", " + bibTeXEscape(name.Given)
+}
+
+// bibTeXEscape escapes the characters that have a special meaning in BibTeX.
+func bibTeXEscape(s string) string {
+	return bibTeXSpecial.ReplaceAllStringFunc(s, func(c string) string {
+		if c == `\` {
+			return `\textbackslash{}`
+		}
+		return `\` + c
+	})
+}
+
+// risTypes maps CSL item types to RIS reference types.
+var risTypes = map[string]string{
+	bibliography.TypeArticleJournal:   "JOUR",
+	bibliography.TypeArticleNewspaper: "NEWS",
+	bibliography.TypeBook:             "BOOK",
+	bibliography.TypeWebpage:          "ELEC",
+}
+
+// WriteRIS writes bibliographic records in the RIS format read by reference managers such as Zotero.
+func WriteRIS(w io.Writer, items []bibliography.Item) error {
+	var sb strings.Builder
+	for _, item := range items {
+		risType, ok := risTypes[item.Type]
+		if !ok {
+			risType = "GEN"
+		}
+		tag := func(name, value string) {
+			if value != "" {
+				fmt.Fprintf(&sb, "%s  - %s\r\n", name, strings.ReplaceAll(value, "\n", " "))
+			}
+		}
+		tag("TY", risType)
+		for _, name := range item.Author {
+			switch {
+			case name.Literal != "":
+				tag("AU", name.Literal)
+			case name.Given == "":
+				tag("AU", name.Family)
+			default:
+				tag("AU", name.Family+", "+name.Given)
+			}
+		}
+		tag("TI", item.Title)
+		tag("T2", item.ContainerTitle)
+		tag("PB", item.Publisher)
+		tag("CY", item.PublisherPlace)
+		if year := item.Issued.Year(); year != 0 {
+			tag("PY", fmt.Sprint(year))
+		}
+		tag("DA", risDate(item.Issued))
+		tag("VL", item.Volume)
+		tag("IS", item.Issue)
+		if item.Page != "" {
+			pages := strings.SplitN(strings.ReplaceAll(item.Page, "–", "-"), "-", 2)
+			tag("SP", pages[0])
+			if len(pages) == 2 {
+				tag("EP", pages[1])
+			}
+		}
+		tag("DO", item.DOI)
+		tag("SN", item.ISBN)
+		tag("AN", item.PMID)
+		tag("UR", item.URL)
+		tag("Y2", risDate(item.Accessed))
+		sb.WriteString("ER  - \r\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// risDate writes a date as "YYYY/MM/DD/", leaving out the parts that are not known.
+func risDate(date *bibliography.Date) string {
+	if date.Year() == 0 || len(date.DateParts[0]) < 2 {
+		return ""
+	}
+	parts := date.DateParts[0]
+	s := fmt.Sprintf("%04d/%02d/", parts[0], parts[1])
+	if len(parts) > 2 {
+		s += fmt.Sprintf("%02d", parts[2])
+	}
+	return s + "/"
+}
+
+// isoDate writes a date as "YYYY-MM-DD", or as much of it as is known.
+func isoDate(date *bibliography.Date) string {
+	if date.Year() == 0 {
+		return ""
+	}
+	var parts []string
+	for i, part := range date.DateParts[0] {
+		if i == 0 {
+			parts = append(parts, fmt.Sprintf("%04d", part))
+		} else {
+			parts = append(parts, fmt.Sprintf("%02d", part))
+		}
+	}
+	return strings.Join(parts, "-")
+}
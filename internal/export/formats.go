@@ -0,0 +1,178 @@
+package export
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteDOT writes the graph in the Graphviz DOT language.
+func WriteDOT(w io.Writer, g *Graph) error {
+	var sb strings.Builder
+	sb.WriteString("digraph citations {\n")
+	fmt.Fprintf(&sb, "\tlabel=%s;\n", dotQuote(g.Label))
+	for _, node := range g.Nodes {
+		shape := "ellipse"
+		switch node.Kind {
+		case NodePage:
+			shape = "box"
+		case NodeClaim:
+			shape = "note"
+		}
+		fmt.Fprintf(&sb, "\t%s [label=%s, kind=%s, shape=%s];\n", dotQuote(node.ID), dotQuote(node.Label), node.Kind, shape)
+	}
+	for _, edge := range g.Edges {
+		label := edge.Relation
+		if edge.Verdict != "" {
+			label += ": " + edge.Verdict
+		}
+		fmt.Fprintf(&sb, "\t%s -> %s [label=%s];\n", dotQuote(edge.Source), dotQuote(edge.Target), dotQuote(label))
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// dotQuote quotes a string as a DOT identifier.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// graphML is the root element of a GraphML document.
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes the graph as a GraphML document.
+func WriteGraphML(w io.Writer, g *Graph) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "kind", For: "node", AttrName: "kind", AttrType: "string"},
+			{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "url", For: "node", AttrName: "url", AttrType: "string"},
+			{ID: "relation", For: "edge", AttrName: "relation", AttrType: "string"},
+			{ID: "verdict", For: "edge", AttrName: "verdict", AttrType: "string"},
+		},
+		Graph: graphMLGraph{ID: "citations", EdgeDefault: "directed"},
+	}
+	for _, node := range g.Nodes {
+		n := graphMLNode{ID: node.ID, Data: []graphMLData{{Key: "kind", Value: node.Kind}, {Key: "label", Value: node.Label}}}
+		if node.URL != "" {
+			n.Data = append(n.Data, graphMLData{Key: "url", Value: node.URL})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, n)
+	}
+	for _, edge := range g.Edges {
+		e := graphMLEdge{Source: edge.Source, Target: edge.Target, Data: []graphMLData{{Key: "relation", Value: edge.Relation}}}
+		if edge.Verdict != "" {
+			e.Data = append(e.Data, graphMLData{Key: "verdict", Value: edge.Verdict})
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, e)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode GraphML: %v", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// jsonGraph is a document in the JSON Graph Format (version 2).
+type jsonGraph struct {
+	Graph jsonGraphGraph `json:"graph"`
+}
+
+type jsonGraphGraph struct {
+	Directed bool                     `json:"directed"`
+	Label    string                   `json:"label"`
+	Nodes    map[string]jsonGraphNode `json:"nodes"`
+	Edges    []jsonGraphEdge          `json:"edges"`
+}
+
+type jsonGraphNode struct {
+	Label    string            `json:"label"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+type jsonGraphEdge struct {
+	Source   string            `json:"source"`
+	Target   string            `json:"target"`
+	Relation string            `json:"relation"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// WriteJSONGraph writes the graph in the JSON Graph Format.
+func WriteJSONGraph(w io.Writer, g *Graph) error {
+	doc := jsonGraph{Graph: jsonGraphGraph{
+		Directed: true,
+		Label:    g.Label,
+		Nodes:    make(map[string]jsonGraphNode, len(g.Nodes)),
+		Edges:    []jsonGraphEdge{},
+	}}
+	for _, node := range g.Nodes {
+		metadata := map[string]string{"kind": node.Kind}
+		if node.URL != "" {
+			metadata["url"] = node.URL
+		}
+		doc.Graph.Nodes[node.ID] = jsonGraphNode{Label: node.Label, Metadata: metadata}
+	}
+	for _, edge := range g.Edges {
+		e := jsonGraphEdge{Source: edge.Source, Target: edge.Target, Relation: edge.Relation}
+		if edge.Verdict != "" {
+			e.Metadata = map[string]string{"verdict": edge.Verdict}
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, e)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode JSON graph: %v", err)
+	}
+	return nil
+}
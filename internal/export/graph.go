@@ -0,0 +1,155 @@
+package export
+
+import (
+	"citation-scanner/internal/parser"
+	"fmt"
+	"io"
+)
+
+// Node kinds of a citation graph.
+const (
+	NodePage   = "page"
+	NodeClaim  = "claim"
+	NodeSource = "source"
+)
+
+// Edge relations of a citation graph.
+const (
+	// RelationAsserts links a page to a claim made on it.
+	RelationAsserts = "asserts"
+	// RelationCites links a claim to a page or source it cites.
+	RelationCites = "cites"
+	// RelationSupports links a source back to a claim it was verified to support, fully or partially, with the verdict.
+	RelationSupports = "supports"
+	// RelationContradicts links a source back to a claim it was verified to contradict.
+	RelationContradicts = "contradicts"
+)
+
+// Graph formats understood by WriteGraph.
+const (
+	FormatDOT       = "dot"
+	FormatGraphML   = "graphml"
+	FormatJSONGraph = "json"
+)
+
+// Graph is a node/edge view of an aggregation, with pages, claims and sources as nodes.
+type Graph struct {
+	Label string
+	Nodes []Node
+	Edges []Edge
+}
+
+// Node is a page, claim or source of a citation graph.
+type Node struct {
+	ID    string
+	Kind  string
+	Label string
+	URL   string
+}
+
+// Edge is a directed relation between two nodes of a citation graph.
+type Edge struct {
+	Source   string
+	Target   string
+	Relation string
+	Verdict  string
+}
+
+// BuildGraph turns an aggregation into a citation graph. Sources that were scanned are represented
+// by their page node, and every other source by a source node.
+func BuildGraph(aggregatedClaims *parser.AggregatedClaims) *Graph {
+	g := &Graph{Label: aggregatedClaims.RootPage}
+	pages := make(map[string]bool)
+	for _, page := range aggregatedClaims.AllClaims {
+		if !pages[page.Page] {
+			pages[page.Page] = true
+			g.Nodes = append(g.Nodes, Node{ID: pageID(page.Page), Kind: NodePage, Label: page.Page, URL: page.Page})
+		}
+	}
+
+	sources := make(map[string]bool)
+	for _, page := range aggregatedClaims.AllClaims {
+		for i, claim := range page.Claims {
+			claimNode := claimID(page.Page, i)
+			g.Nodes = append(g.Nodes, Node{ID: claimNode, Kind: NodeClaim, Label: claim.Claim})
+			g.Edges = append(g.Edges, Edge{Source: pageID(page.Page), Target: claimNode, Relation: RelationAsserts})
+
+			for _, source := range claim.Source {
+				target := pageID(source)
+				if !pages[source] {
+					target = sourceID(source)
+					if !sources[source] {
+						sources[source] = true
+						g.Nodes = append(g.Nodes, Node{ID: target, Kind: NodeSource, Label: source, URL: source})
+					}
+				}
+				g.Edges = append(g.Edges, Edge{Source: claimNode, Target: target, Relation: RelationCites})
+
+				// Sources that do not address the claim get no edge back to it
+				switch verdict := verdictOf(claim, source); parser.Verdict(verdict) {
+				case parser.VerdictSupports, parser.VerdictPartiallySupports:
+					g.Edges = append(g.Edges, Edge{Source: target, Target: claimNode, Relation: RelationSupports, Verdict: verdict})
+				case parser.VerdictContradicts:
+					g.Edges = append(g.Edges, Edge{Source: target, Target: claimNode, Relation: RelationContradicts, Verdict: verdict})
+				}
+			}
+		}
+	}
+	return g
+}
+
+// CheckGraphFormat returns an error if the format is not one WriteGraph understands, so that callers can
+// reject it before doing the work of a scan.
+func CheckGraphFormat(format string) error {
+	switch format {
+	case FormatDOT, FormatGraphML, FormatJSONGraph:
+		return nil
+	}
+	return fmt.Errorf("unknown graph format %q", format)
+}
+
+// WriteGraph writes the graph in the given format.
+func WriteGraph(w io.Writer, g *Graph, format string) error {
+	switch format {
+	case FormatDOT:
+		return WriteDOT(w, g)
+	case FormatGraphML:
+		return WriteGraphML(w, g)
+	case FormatJSONGraph:
+		return WriteJSONGraph(w, g)
+	}
+	return CheckGraphFormat(format)
+}
+
+// GraphContentType returns the media type of a graph format.
+func GraphContentType(format string) string {
+	switch format {
+	case FormatDOT:
+		return "text/vnd.graphviz"
+	case FormatGraphML:
+		return "application/graphml+xml"
+	}
+	return "application/json"
+}
+
+// verdictOf returns the verification verdict for a source of a claim, if it was verified.
+func verdictOf(claim parser.Claim, source string) string {
+	for _, record := range claim.Details {
+		if record.URL == source && record.Verification != nil {
+			return string(record.Verification.Verdict)
+		}
+	}
+	return ""
+}
+
+func pageID(url string) string {
+	return "page:" + url
+}
+
+func sourceID(url string) string {
+	return "source:" + url
+}
+
+func claimID(page string, index int) string {
+	return fmt.Sprintf("claim:%s#%d", page, index)
+}
@@ -0,0 +1,122 @@
+package export
+
+import (
+	"citation-scanner/internal/parser"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+// testAggregation returns a small aggregation with one scanned source, one unscanned source and a verdict.
+func testAggregation() *parser.AggregatedClaims {
+	return &parser.AggregatedClaims{
+		RootPage: "https://example.org/root",
+		AllClaims: []parser.ParsedClaims{
+			{
+				Page: "https://example.org/root",
+				Claims: []parser.Claim{{
+					Claim:  `The "first" claim.`,
+					Source: []string{"https://example.org/source", "https://example.net/unscanned"},
+					Details: []parser.SourceRecord{{
+						URL:          "https://example.org/source",
+						Verification: &parser.Verification{Verdict: parser.VerdictSupports},
+					}},
+				}},
+			},
+			{
+				Page:      "https://example.org/source",
+				ParentURL: "https://example.org/root",
+				Claims:    []parser.Claim{{Claim: "The second claim.", Source: []string{}}},
+			},
+		},
+	}
+}
+
+// TestBuildGraph verifies the nodes and edges built from an aggregation.
+func TestBuildGraph(t *testing.T) {
+	g := BuildGraph(testAggregation())
+
+	kinds := make(map[string]int)
+	for _, node := range g.Nodes {
+		kinds[node.Kind]++
+	}
+	if kinds[NodePage] != 2 || kinds[NodeClaim] != 2 || kinds[NodeSource] != 1 {
+		t.Errorf("Expected 2 pages, 2 claims and 1 source, got %v", kinds)
+	}
+
+	relations := make(map[string]int)
+	for _, edge := range g.Edges {
+		relations[edge.Relation]++
+		if edge.Relation == RelationSupports && (edge.Source != "page:https://example.org/source" || edge.Verdict != "supports") {
+			t.Errorf("Unexpected supports edge: %+v", edge)
+		}
+	}
+	if relations[RelationAsserts] != 2 || relations[RelationCites] != 2 || relations[RelationSupports] != 1 {
+		t.Errorf("Expected 2 asserts, 2 cites and 1 supports edges, got %v", relations)
+	}
+}
+
+// TestBuildGraphVerdicts verifies that only supporting verdicts become supports edges, contradictions get their own relation
+// and sources that do not address the claim get no edge back.
+func TestBuildGraphVerdicts(t *testing.T) {
+	aggregatedClaims := testAggregation()
+	claim := &aggregatedClaims.AllClaims[0].Claims[0]
+	claim.Details[0].Verification.Verdict = parser.VerdictContradicts
+	claim.Details = append(claim.Details, parser.SourceRecord{
+		URL:          "https://example.net/unscanned",
+		Verification: &parser.Verification{Verdict: parser.VerdictNotFound},
+	})
+
+	relations := make(map[string]int)
+	for _, edge := range BuildGraph(aggregatedClaims).Edges {
+		relations[edge.Relation]++
+	}
+	if relations[RelationSupports] != 0 || relations[RelationContradicts] != 1 {
+		t.Errorf("Expected a single contradicts edge and no supports edges, got %v", relations)
+	}
+}
+
+// TestWriteGraphFormats verifies that every format produces a well-formed document.
+func TestWriteGraphFormats(t *testing.T) {
+	g := BuildGraph(testAggregation())
+
+	var dot strings.Builder
+	if err := WriteGraph(&dot, g, FormatDOT); err != nil {
+		t.Fatalf("Failed to write DOT: %v", err)
+	}
+	if !strings.HasPrefix(dot.String(), "digraph citations {") || !strings.Contains(dot.String(), `\"first\"`) {
+		t.Errorf("Unexpected DOT output:\n%s", dot.String())
+	}
+	if !strings.Contains(dot.String(), `[label="supports: supports"]`) {
+		t.Errorf("Expected the verdict on the supports edge:\n%s", dot.String())
+	}
+
+	var graphml strings.Builder
+	if err := WriteGraph(&graphml, g, FormatGraphML); err != nil {
+		t.Fatalf("Failed to write GraphML: %v", err)
+	}
+	var doc graphML
+	if err := xml.Unmarshal([]byte(graphml.String()), &doc); err != nil {
+		t.Fatalf("Failed to parse GraphML: %v", err)
+	}
+	if len(doc.Graph.Nodes) != len(g.Nodes) || len(doc.Graph.Edges) != len(g.Edges) {
+		t.Errorf("Expected %d nodes and %d edges in GraphML, got %d and %d", len(g.Nodes), len(g.Edges), len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+
+	var jsonOutput strings.Builder
+	if err := WriteGraph(&jsonOutput, g, FormatJSONGraph); err != nil {
+		t.Fatalf("Failed to write JSON graph: %v", err)
+	}
+	var jg jsonGraph
+	if err := json.Unmarshal([]byte(jsonOutput.String()), &jg); err != nil {
+		t.Fatalf("Failed to parse JSON graph: %v", err)
+	}
+	if !jg.Graph.Directed || len(jg.Graph.Nodes) != len(g.Nodes) || len(jg.Graph.Edges) != len(g.Edges) {
+		t.Errorf("Unexpected JSON graph: %+v", jg.Graph)
+	}
+
+	if err := WriteGraph(&strings.Builder{}, g, "svg"); err == nil {
+		t.Error("Expected an error for an unknown format, got nil")
+	}
+}
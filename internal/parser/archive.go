@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"citation-scanner/pkg/archive"
+)
+
+// markArchived records on the source records the snapshot of every source that could only be read from an archive,
+// whether it was scanned as a page or fetched for verification.
+func markArchived(pages []ParsedClaims, fetched map[string]*fetchedPage) {
+	snapshots := make(map[string]*archive.Snapshot)
+	for _, page := range pages {
+		if page.Archived != nil {
+			snapshots[page.Page] = page.Archived
+		}
+	}
+	for url, page := range fetched {
+		if page.archived != nil {
+			snapshots[url] = page.archived
+		}
+	}
+	if len(snapshots) == 0 {
+		return
+	}
+
+	for p := range pages {
+		for c := range pages[p].Claims {
+			claim := &pages[p].Claims[c]
+			for _, source := range claim.Source {
+				if snapshot, ok := snapshots[source]; ok {
+					claim.sourceRecord(source).Archive = snapshot
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"citation-scanner/pkg/archive"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// deadSource is the fixture source that is unreachable live in the archive tests.
+const deadSource = "https://example.org/papers/fukuyama"
+
+// newTestArchive serves a stand-in availability API with a snapshot of the dead source only.
+func newTestArchive(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("url") != deadSource {
+			fmt.Fprint(w, `{"archived_snapshots": {}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"archived_snapshots": {"closest": {"available": true, "status": "200", "timestamp": "20210301000000", "url": "http://%s/web/20210301000000/%s"}}}`, r.Host, deadSource)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// archiveFetcher serves the fixtures, except for the dead source, which is only served from its snapshot.
+func archiveFetcher(url string) (string, error) {
+	if url == deadSource {
+		return "", fmt.Errorf("unexpected HTTP status: 404 Not Found")
+	}
+	if strings.HasSuffix(url, "/web/20210301000000id_/"+deadSource) {
+		return fixtureFetcher(deadSource)
+	}
+	return fixtureFetcher(url)
+}
+
+// TestParseAndAggregateClaimsArchiveFallback verifies that a dead source is scanned from its archived copy and marked as archived.
+func TestParseAndAggregateClaimsArchiveFallback(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+	server := newTestArchive(t)
+	opts := append(replayOptions(t), WithFetcher(archiveFetcher), WithArchiveFallback(archive.NewResolver(archive.WithBaseURL(server.URL))))
+
+	aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, 1, opts...)
+	if err != nil {
+		t.Fatalf("Error aggregating claims: %v", err)
+	}
+	if len(aggregatedClaims.Errors) != 0 || len(aggregatedClaims.AllClaims) != 3 {
+		t.Fatalf("Expected all 3 pages to be scanned without errors, got %d pages and errors %v", len(aggregatedClaims.AllClaims), aggregatedClaims.Errors)
+	}
+
+	for _, page := range aggregatedClaims.AllClaims {
+		if page.Page == deadSource && (page.Archived == nil || page.Archived.Timestamp.Year() != 2021) {
+			t.Errorf("Expected %s to be scanned from the snapshot found in the archive, got %+v", deadSource, page.Archived)
+		}
+	}
+	claim := aggregatedClaims.AllClaims[0].Claims[0]
+	if record := claim.sourceRecord(deadSource); record.Archive == nil || record.Archive.Timestamp.Year() != 2021 {
+		t.Errorf("Expected the source record of %s to carry its snapshot, got %+v", deadSource, record.Archive)
+	}
+}
+
+// TestParsePageClaimsNoArchivedCopy verifies that the original error is kept when a page has no archived copy.
+func TestParsePageClaimsNoArchivedCopy(t *testing.T) {
+	server := newTestArchive(t)
+	opts := append(replayOptions(t), WithArchiveFallback(archive.NewResolver(archive.WithBaseURL(server.URL))))
+
+	_, err := ParsePageClaims("https://example.org/missing", opts...)
+	if err == nil || !strings.Contains(err.Error(), "404 Not Found; no archived copy found") {
+		t.Errorf("Expected the fetch error and the missing copy to be reported, got %v", err)
+	}
+}
+
+// TestFetchPageArchiveAccessDate verifies that the archive is asked for the copy closest to the access date of the
+// citation, and that a copy the archive captured as an error page is not used.
+func TestFetchPageArchiveAccessDate(t *testing.T) {
+	var timestamps []string
+	status := "200"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timestamps = append(timestamps, r.URL.Query().Get("timestamp"))
+		fmt.Fprintf(w, `{"archived_snapshots": {"closest": {"available": true, "status": %q, "timestamp": "20190510000000", "url": "http://%s/web/20190510000000/%s"}}}`, status, r.Host, deadSource)
+	}))
+	t.Cleanup(server.Close)
+	o := applyOptions(
+		WithFetcher(func(url string) (string, error) {
+			if url == deadSource {
+				return "", fmt.Errorf("unexpected HTTP status: 404 Not Found")
+			}
+			return "<html></html>", nil
+		}),
+		WithArchiveFallback(archive.NewResolver(archive.WithBaseURL(server.URL))),
+	)
+	hint := archiveHint{accessed: time.Date(2019, time.May, 10, 0, 0, 0, 0, time.UTC)}
+
+	if _, _, snapshot, err := fetchPage(deadSource, hint, o); err != nil || snapshot == nil {
+		t.Fatalf("Expected the archived copy to be fetched, got %+v and %v", snapshot, err)
+	}
+	if len(timestamps) != 1 || timestamps[0] != "20190510000000" {
+		t.Errorf("Expected the archive to be asked for the copy of the access date, got %v", timestamps)
+	}
+
+	status = "404"
+	if _, _, _, err := fetchPage(deadSource, hint, o); err == nil || !strings.Contains(err.Error(), "archived copy has HTTP status 404") {
+		t.Errorf("Expected a copy captured as an error page to be rejected, got %v", err)
+	}
+}
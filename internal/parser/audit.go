@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"citation-scanner/pkg/linkcheck"
+	"citation-scanner/pkg/webscraper"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// LinkAudit is the state of every link checked for a page, with the number of links per verdict.
+type LinkAudit struct {
+	Page    string                    `json:"page"`
+	Links   []linkcheck.Result        `json:"links"`
+	Summary map[linkcheck.Verdict]int `json:"summary"`
+}
+
+// AuditClaims checks every source of the parsed claims for dead links, redirects, soft 404s and parked domains,
+// without fetching the sources for extraction or using the LLM. The results are also stored on the claims' source records.
+func AuditClaims(parsedClaims *ParsedClaims, opts ...func(*Options)) *LinkAudit {
+	o := applyOptions(opts...)
+
+	// Sources given as bare identifiers are checked at their landing page
+	var sources []string
+	seen := make(map[string]bool)
+	for _, claim := range parsedClaims.Claims {
+		for _, source := range claim.Source {
+			if link := o.identifiers.FetchURL(source); !seen[link] {
+				seen[link] = true
+				sources = append(sources, link)
+			}
+		}
+	}
+
+	audit := newLinkAudit(parsedClaims.Page, o.linkChecker.CheckAll(sources))
+	results := make(map[string]*linkcheck.Result, len(audit.Links))
+	for i := range audit.Links {
+		results[audit.Links[i].URL] = &audit.Links[i]
+	}
+	for i := range parsedClaims.Claims {
+		claim := &parsedClaims.Claims[i]
+		for _, source := range claim.Source {
+			claim.sourceRecord(source).Link = results[o.identifiers.FetchURL(source)]
+		}
+	}
+	return audit
+}
+
+// AuditPage fetches a page and checks the links in its body that lead to other sites, without using the LLM.
+func AuditPage(pageURL string, opts ...func(*Options)) (*LinkAudit, error) {
+	o := applyOptions(opts...)
+
+	page, err := o.fetch(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scrape the page: %v", err)
+	}
+	links, err := webscraper.Links(page, pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scrape the page: %v", err)
+	}
+
+	// Links within the same site are navigation rather than citations
+	var external []string
+	site := hostSite(pageURL)
+	for _, link := range links {
+		if hostSite(link) != site {
+			external = append(external, link)
+		}
+	}
+	return newLinkAudit(pageURL, o.linkChecker.CheckAll(external)), nil
+}
+
+// newLinkAudit counts the results of a page's links by verdict.
+func newLinkAudit(page string, results []linkcheck.Result) *LinkAudit {
+	audit := &LinkAudit{Page: page, Links: results, Summary: make(map[linkcheck.Verdict]int)}
+	if audit.Links == nil {
+		audit.Links = []linkcheck.Result{}
+	}
+	for _, result := range results {
+		audit.Summary[result.Verdict]++
+	}
+	return audit
+}
+
+// hostSite returns the site of a URL's host, or "" if the URL is invalid.
+func hostSite(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return siteOf(strings.ToLower(parsed.Hostname()))
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"citation-scanner/pkg/linkcheck"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newAuditServer serves a live source and a dead one.
+func newAuditServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/live", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><head><title>Live source</title></head><body></body></html>")
+	})
+	mux.HandleFunc("/dead", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestAuditClaims verifies that each source is checked once and the results are recorded on the source records.
+func TestAuditClaims(t *testing.T) {
+	server := newAuditServer(t)
+	parsedClaims := &ParsedClaims{Page: rootFixtureURL, Claims: []Claim{
+		{Claim: "First claim.", Source: []string{server.URL + "/live", server.URL + "/dead"}},
+		{Claim: "Second claim.", Source: []string{server.URL + "/dead"}},
+		{Claim: "Third claim.", Source: []string{}},
+	}}
+
+	audit := AuditClaims(parsedClaims)
+	if len(audit.Links) != 2 || audit.Summary[linkcheck.VerdictOK] != 1 || audit.Summary[linkcheck.VerdictDead] != 1 {
+		t.Fatalf("Expected 1 live and 1 dead link, got %+v", audit)
+	}
+	record := parsedClaims.Claims[1].sourceRecord(server.URL + "/dead")
+	if record.Link == nil || record.Link.Status != http.StatusGone {
+		t.Errorf("Expected the dead link result on the second claim, got %+v", record.Link)
+	}
+}
+
+// TestAuditPage verifies that the links of a page to other sites are checked without using the LLM.
+func TestAuditPage(t *testing.T) {
+	server := newAuditServer(t)
+	page := fmt.Sprintf(`<html><head><link href="%[1]s/style.css"></head><body>
+		<a href="/wiki/Other">Other article</a>
+		<a href="#cite_note-1">[1]</a>
+		<a href="%[1]s/live">Live</a> <a href="%[1]s/dead#page=2">Dead</a> <a href="%[1]s/live">Live again</a>
+		<a href="mailto:editor@example.org">Contact</a>
+	</body></html>`, server.URL)
+	fetch := func(string) (string, error) { return page, nil }
+
+	audit, err := AuditPage(rootFixtureURL, WithFetcher(fetch))
+	if err != nil {
+		t.Fatalf("Error auditing page: %v", err)
+	}
+	if len(audit.Links) != 2 || audit.Links[0].URL != server.URL+"/live" || audit.Links[1].URL != server.URL+"/dead" {
+		t.Fatalf("Expected the 2 external links to be checked, got %+v", audit.Links)
+	}
+	if audit.Links[1].Verdict != linkcheck.VerdictDead {
+		t.Errorf("Expected the second link to be dead, got %s", audit.Links[1].Verdict)
+	}
+}
@@ -0,0 +1,38 @@
+package parser
+
+import (
+	"fmt"
+	"time"
+)
+
+// Budget limits the work a recursive scan may do. A zero value for any field means no limit.
+// Limits are checked before each page is started, so pages already in flight may overshoot
+// the token and cost limits by at most their own usage.
+type Budget struct {
+	MaxPages    int
+	MaxTokens   int64
+	MaxCost     float64
+	MaxDuration time.Duration
+}
+
+// SkippedURL is a URL that was discovered during a scan but not parsed, and the reason why.
+type SkippedURL struct {
+	URL       string `json:"url"`
+	ParentURL string `json:"parent_url,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// exhausted returns which limit of the budget has been reached, or an empty string if the scan may continue.
+func (b Budget) exhausted(pages int, usage Usage, elapsed time.Duration) string {
+	switch {
+	case b.MaxPages > 0 && pages >= b.MaxPages:
+		return fmt.Sprintf("budget exhausted: max pages (%d) reached", b.MaxPages)
+	case b.MaxTokens > 0 && usage.TotalTokens >= b.MaxTokens:
+		return fmt.Sprintf("budget exhausted: max tokens (%d) reached", b.MaxTokens)
+	case b.MaxCost > 0 && usage.EstimatedCost >= b.MaxCost:
+		return fmt.Sprintf("budget exhausted: max cost ($%.2f) reached", b.MaxCost)
+	case b.MaxDuration > 0 && elapsed >= b.MaxDuration:
+		return fmt.Sprintf("budget exhausted: max duration (%s) reached", b.MaxDuration)
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestBudgetExhausted verifies which limit is reported once it has been reached.
+func TestBudgetExhausted(t *testing.T) {
+	tests := []struct {
+		name    string
+		budget  Budget
+		pages   int
+		usage   Usage
+		elapsed time.Duration
+		want    string
+	}{
+		{"no limits", Budget{}, 100, Usage{TotalTokens: 1e9, EstimatedCost: 1e3}, time.Hour, ""},
+		{"under limits", Budget{MaxPages: 5, MaxTokens: 100, MaxCost: 1, MaxDuration: time.Minute}, 4, Usage{TotalTokens: 99, EstimatedCost: 0.5}, time.Second, ""},
+		{"pages", Budget{MaxPages: 5}, 5, Usage{}, 0, "max pages"},
+		{"tokens", Budget{MaxTokens: 100}, 0, Usage{TotalTokens: 100}, 0, "max tokens"},
+		{"cost", Budget{MaxCost: 1}, 0, Usage{EstimatedCost: 1.5}, 0, "max cost"},
+		{"duration", Budget{MaxDuration: time.Minute}, 0, Usage{}, 2 * time.Minute, "max duration"},
+	}
+
+	for _, tt := range tests {
+		got := tt.budget.exhausted(tt.pages, tt.usage, tt.elapsed)
+		if tt.want == "" && got != "" {
+			t.Errorf("%s: expected no limit to be reached, got %q", tt.name, got)
+		}
+		if tt.want != "" && !strings.Contains(got, tt.want) {
+			t.Errorf("%s: expected %q to be reported, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+// TestParseAndAggregateClaimsBudget verifies that pages beyond the budget are skipped and reported.
+func TestParseAndAggregateClaimsBudget(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+
+	opts := append(replayOptions(t), WithBudget(Budget{MaxPages: 2}))
+	aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, 1, opts...)
+	if err != nil {
+		t.Fatalf("Error parsing and aggregating claims: %v", err)
+	}
+
+	if len(aggregatedClaims.AllClaims) != 2 {
+		t.Errorf("Expected claims from 2 pages, got %d", len(aggregatedClaims.AllClaims))
+	}
+	if len(aggregatedClaims.Skipped) != 1 {
+		t.Fatalf("Expected 1 skipped URL, got %v", aggregatedClaims.Skipped)
+	}
+	skipped := aggregatedClaims.Skipped[0]
+	if skipped.URL != "https://example.net/survey" || skipped.ParentURL != rootFixtureURL || !strings.Contains(skipped.Reason, "max pages") {
+		t.Errorf("Unexpected skipped URL: %+v", skipped)
+	}
+}
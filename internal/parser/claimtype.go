@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Types of claims, assigned by the LLM during extraction.
+const (
+	ClaimStatistic  = "statistic"
+	ClaimQuotation  = "quotation"
+	ClaimCausal     = "causal"
+	ClaimDefinition = "definition"
+	ClaimOpinion    = "opinion"
+	ClaimEvent      = "event"
+)
+
+// ClaimTypes lists every claim type in the order they are described to the LLM.
+var ClaimTypes = []string{ClaimStatistic, ClaimQuotation, ClaimCausal, ClaimDefinition, ClaimOpinion, ClaimEvent}
+
+// normalizeType lowercases the claim's type, clears types the LLM made up, and keeps a speaker only for quotations.
+func (c *Claim) normalizeType() {
+	c.Type = strings.ToLower(strings.TrimSpace(c.Type))
+	if !isClaimType(c.Type) {
+		c.Type = ""
+	}
+	c.Speaker = strings.TrimSpace(c.Speaker)
+	if c.Type != ClaimQuotation {
+		c.Speaker = ""
+	}
+}
+
+// isClaimType reports whether t is one of the known claim types.
+func isClaimType(t string) bool {
+	for _, claimType := range ClaimTypes {
+		if t == claimType {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseClaimTypes splits a comma-separated list of claim types, such as "statistic,quotation", and rejects unknown ones.
+func ParseClaimTypes(list string) ([]string, error) {
+	var types []string
+	for _, t := range strings.Split(list, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		if !isClaimType(t) {
+			return nil, fmt.Errorf("unknown claim type %q, expected one of %s", t, strings.Join(ClaimTypes, ", "))
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// FilterClaims returns a copy of the parsed claims that keeps only the claims of the given types.
+// Without types, all claims are kept.
+func FilterClaims(parsedClaims *ParsedClaims, types ...string) *ParsedClaims {
+	filtered := *parsedClaims
+	if len(types) == 0 {
+		return &filtered
+	}
+	filtered.Claims = []Claim{}
+	for _, claim := range parsedClaims.Claims {
+		for _, t := range types {
+			if claim.Type == t {
+				filtered.Claims = append(filtered.Claims, claim)
+				break
+			}
+		}
+	}
+	return &filtered
+}
+
+// FilterAggregatedClaims returns a copy of the aggregated claims that keeps only the claims of the given types on every page.
+func FilterAggregatedClaims(aggregatedClaims *AggregatedClaims, types ...string) *AggregatedClaims {
+	filtered := *aggregatedClaims
+	filtered.AllClaims = make([]ParsedClaims, len(aggregatedClaims.AllClaims))
+	for i := range aggregatedClaims.AllClaims {
+		filtered.AllClaims[i] = *FilterClaims(&aggregatedClaims.AllClaims[i], types...)
+	}
+	return &filtered
+}
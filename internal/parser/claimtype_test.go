@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"testing"
+)
+
+// TestParsePageClaimsTypes verifies that the claim types returned by the LLM are kept on the claims.
+func TestParsePageClaimsTypes(t *testing.T) {
+	parsedClaims, err := ParsePageClaims(rootFixtureURL, replayOptions(t)...)
+	if err != nil {
+		t.Fatalf("Error parsing claims: %v", err)
+	}
+
+	want := []string{ClaimCausal, ClaimStatistic, ClaimDefinition, ClaimOpinion, ClaimEvent}
+	for i, claim := range parsedClaims.Claims {
+		if claim.Type != want[i] {
+			t.Errorf("Expected claim %q to be a %s, got %q", claim.Claim, want[i], claim.Type)
+		}
+	}
+
+	statistics := FilterClaims(parsedClaims, ClaimStatistic, ClaimQuotation)
+	if len(statistics.Claims) != 1 || statistics.Claims[0].Type != ClaimStatistic {
+		t.Errorf("Expected the filter to keep 1 statistic, got %+v", statistics.Claims)
+	}
+	if len(parsedClaims.Claims) != 5 {
+		t.Errorf("Expected filtering to leave the original 5 claims untouched, got %d", len(parsedClaims.Claims))
+	}
+}
+
+// TestNormalizeType verifies that unknown types are cleared and speakers are only kept for quotations.
+func TestNormalizeType(t *testing.T) {
+	tests := []struct {
+		claim       Claim
+		wantType    string
+		wantSpeaker string
+	}{
+		{Claim{Type: " Quotation ", Speaker: " Ada Lovelace "}, ClaimQuotation, "Ada Lovelace"},
+		{Claim{Type: "statistic", Speaker: "Ada Lovelace"}, ClaimStatistic, ""},
+		{Claim{Type: "rumour"}, "", ""},
+	}
+
+	for _, tt := range tests {
+		claim := tt.claim
+		claim.normalizeType()
+		if claim.Type != tt.wantType || claim.Speaker != tt.wantSpeaker {
+			t.Errorf("Expected %+v to normalize to type %q and speaker %q, got %q and %q", tt.claim, tt.wantType, tt.wantSpeaker, claim.Type, claim.Speaker)
+		}
+	}
+}
+
+// TestParseClaimTypes verifies that comma-separated type lists are parsed and unknown types rejected.
+func TestParseClaimTypes(t *testing.T) {
+	types, err := ParseClaimTypes("Statistic, quotation,")
+	if err != nil {
+		t.Fatalf("Error parsing claim types: %v", err)
+	}
+	if len(types) != 2 || types[0] != ClaimStatistic || types[1] != ClaimQuotation {
+		t.Errorf("Expected [statistic quotation], got %v", types)
+	}
+	if _, err := ParseClaimTypes("statistic,rumour"); err == nil {
+		t.Error("Expected an error for an unknown claim type, got nil")
+	}
+}
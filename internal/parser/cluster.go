@@ -0,0 +1,186 @@
+package parser
+
+import (
+	"citation-scanner/pkg/openai"
+	"fmt"
+	"math"
+)
+
+// DefaultClusterThreshold is the minimum word similarity for two claims to be grouped together.
+const DefaultClusterThreshold = 0.6
+
+// DefaultEmbeddingClusterThreshold is the minimum cosine similarity of embeddings for two claims to be grouped together.
+const DefaultEmbeddingClusterThreshold = 0.88
+
+// embeddingBatchSize is the number of claims sent in a single embedding request.
+const embeddingBatchSize = 256
+
+// ClaimCluster is a group of equivalent claims made across the pages of an aggregation.
+type ClaimCluster struct {
+	ID      int             `json:"id"`
+	Claim   string          `json:"claim"`
+	Members []ClusterMember `json:"members"`
+	Pages   []string        `json:"pages"`
+	Sources []string        `json:"sources"`
+}
+
+// ClusterMember is a claim of a cluster and its similarity to the claim the cluster was started from.
+type ClusterMember struct {
+	Page       string  `json:"page"`
+	Claim      string  `json:"claim"`
+	Similarity float64 `json:"similarity"`
+}
+
+// clusterItem is a claim of the aggregation being clustered.
+type clusterItem struct {
+	page, claim int
+	terms       map[string]bool
+	vector      []float64
+}
+
+// ClusterClaims groups the equivalent claims of an aggregation, sets each claim's ClusterID and stores
+// the clusters on the aggregation. Claims are compared by their words, or by the cosine similarity of
+// their embeddings when an embedder is given, whose usage is added to the aggregation.
+// A threshold of zero selects the default for the chosen comparison.
+func ClusterClaims(aggregatedClaims *AggregatedClaims, threshold float64, embedder openai.Embedder, prices map[string]ModelPrice) error {
+	var items []clusterItem
+	var texts []string
+	for p, page := range aggregatedClaims.AllClaims {
+		for c, claim := range page.Claims {
+			items = append(items, clusterItem{page: p, claim: c, terms: claimTerms(claim.Claim)})
+			texts = append(texts, claim.Claim)
+		}
+	}
+
+	var leaders []int
+	var similarity func(a, b int) float64
+	if embedder == nil {
+		if threshold == 0 {
+			threshold = DefaultClusterThreshold
+		}
+		similarity = func(a, b int) float64 { return termSimilarity(items[a].terms, items[b].terms) }
+		leaders = clusterByTerms(items, threshold, similarity)
+	} else {
+		if threshold == 0 {
+			threshold = DefaultEmbeddingClusterThreshold
+		}
+		for start := 0; start < len(texts); start += embeddingBatchSize {
+			end := min(start+embeddingBatchSize, len(texts))
+			embeddings, err := embedder.Embed(texts[start:end])
+			if err != nil {
+				return fmt.Errorf("failed to embed claims: %v", err)
+			}
+			if len(embeddings.Vectors) != end-start {
+				return fmt.Errorf("expected %d embeddings, got %d", end-start, len(embeddings.Vectors))
+			}
+			aggregatedClaims.Usage.addRequest(embeddings.Model, embeddings.Usage, prices)
+			for i, vector := range embeddings.Vectors {
+				items[start+i].vector = vector
+			}
+		}
+		similarity = func(a, b int) float64 { return cosineSimilarity(items[a].vector, items[b].vector) }
+		leaders = clusterByScan(items, threshold, similarity)
+	}
+
+	// Describe each cluster and point its claims at it
+	clusters := make([]ClaimCluster, 0)
+	index := make(map[int]int)
+	for i, leader := range leaders {
+		if _, exists := index[leader]; !exists {
+			index[leader] = len(clusters)
+			clusters = append(clusters, ClaimCluster{ID: len(clusters) + 1, Claim: texts[leader]})
+		}
+		cluster := &clusters[index[leader]]
+		page := &aggregatedClaims.AllClaims[items[i].page]
+		claim := &page.Claims[items[i].claim]
+		claim.ClusterID = cluster.ID
+
+		cluster.Members = append(cluster.Members, ClusterMember{Page: page.Page, Claim: claim.Claim, Similarity: similarity(i, leader)})
+		cluster.Pages = appendUnique(cluster.Pages, page.Page)
+		for _, source := range claim.Source {
+			cluster.Sources = appendUnique(cluster.Sources, source)
+		}
+	}
+	for i := range clusters {
+		if clusters[i].Sources == nil {
+			clusters[i].Sources = []string{}
+		}
+	}
+	aggregatedClaims.Clusters = clusters
+	return nil
+}
+
+// clusterByTerms assigns every item to the most similar earlier cluster leader, or makes it a leader
+// itself. Only leaders sharing at least one word with the item are compared. It returns the leader of each item.
+func clusterByTerms(items []clusterItem, threshold float64, similarity func(a, b int) float64) []int {
+	leaders := make([]int, len(items))
+	byTerm := make(map[string][]int)
+	for i, item := range items {
+		best, bestSimilarity := i, 0.0
+		compared := make(map[int]bool)
+		for term := range item.terms {
+			for _, leader := range byTerm[term] {
+				if compared[leader] {
+					continue
+				}
+				compared[leader] = true
+				if s := similarity(i, leader); s >= threshold && (s > bestSimilarity || (s == bestSimilarity && leader < best)) {
+					best, bestSimilarity = leader, s
+				}
+			}
+		}
+		leaders[i] = best
+		if best == i {
+			for term := range item.terms {
+				byTerm[term] = append(byTerm[term], i)
+			}
+		}
+	}
+	return leaders
+}
+
+// clusterByScan assigns every item to the most similar earlier cluster leader, comparing against all leaders.
+func clusterByScan(items []clusterItem, threshold float64, similarity func(a, b int) float64) []int {
+	leaders := make([]int, len(items))
+	var heads []int
+	for i := range items {
+		best, bestSimilarity := i, 0.0
+		for _, leader := range heads {
+			if s := similarity(i, leader); s >= threshold && s > bestSimilarity {
+				best, bestSimilarity = leader, s
+			}
+		}
+		leaders[i] = best
+		if best == i {
+			heads = append(heads, i)
+		}
+	}
+	return leaders
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors.
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// appendUnique appends a value to a slice unless it is already present.
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
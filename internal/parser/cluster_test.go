@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"citation-scanner/pkg/openai"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// clusterTestAggregation returns an aggregation where the same fact is worded differently on three pages.
+func clusterTestAggregation() *AggregatedClaims {
+	return &AggregatedClaims{
+		RootPage: "root",
+		AllClaims: []ParsedClaims{
+			{Page: "root", Claims: []Claim{
+				{Claim: "The Eiffel Tower was completed in 1889.[1]", Source: []string{"history"}},
+				{Claim: "Paris is the capital of France.", Source: []string{}},
+			}},
+			{Page: "history", Claims: []Claim{
+				{Claim: "Completed in 1889, the Eiffel Tower ...", Source: []string{"archive"}},
+			}},
+			{Page: "travel", Claims: []Claim{
+				{Claim: "The Eiffel Tower was completed in the year 1889.", Source: []string{"guide"}},
+				{Claim: "The Louvre is the most visited museum in the world.", Source: []string{"guide"}},
+			}},
+		},
+	}
+}
+
+// TestClusterClaims verifies that equivalent claims share a cluster listing every page and source asserting them.
+func TestClusterClaims(t *testing.T) {
+	aggregatedClaims := clusterTestAggregation()
+	if err := ClusterClaims(aggregatedClaims, 0, nil, DefaultPriceTable); err != nil {
+		t.Fatalf("Error clustering claims: %v", err)
+	}
+
+	if len(aggregatedClaims.Clusters) != 3 {
+		t.Fatalf("Expected 3 clusters, got %d: %+v", len(aggregatedClaims.Clusters), aggregatedClaims.Clusters)
+	}
+	tower := aggregatedClaims.Clusters[0]
+	if tower.ID != 1 || len(tower.Members) != 3 {
+		t.Errorf("Expected cluster 1 to hold the 3 Eiffel Tower claims, got %+v", tower)
+	}
+	if want := []string{"root", "history", "travel"}; !reflect.DeepEqual(tower.Pages, want) {
+		t.Errorf("Expected pages %v, got %v", want, tower.Pages)
+	}
+	if want := []string{"history", "archive", "guide"}; !reflect.DeepEqual(tower.Sources, want) {
+		t.Errorf("Expected sources %v, got %v", want, tower.Sources)
+	}
+
+	ids := []int{
+		aggregatedClaims.AllClaims[0].Claims[0].ClusterID,
+		aggregatedClaims.AllClaims[1].Claims[0].ClusterID,
+		aggregatedClaims.AllClaims[2].Claims[0].ClusterID,
+	}
+	if ids[0] != 1 || ids[1] != 1 || ids[2] != 1 {
+		t.Errorf("Expected every Eiffel Tower claim in cluster 1, got %v", ids)
+	}
+	if aggregatedClaims.AllClaims[0].Claims[1].ClusterID == 1 || aggregatedClaims.AllClaims[2].Claims[1].ClusterID == 1 {
+		t.Error("Expected unrelated claims to be in other clusters")
+	}
+}
+
+// fakeEmbedder embeds claims as vectors of keyword counts.
+type fakeEmbedder struct{}
+
+func (fakeEmbedder) Embed(texts []string) (*openai.Embeddings, error) {
+	keywords := []string{"eiffel", "paris", "louvre"}
+	embeddings := &openai.Embeddings{Model: "text-embedding-3-small", Usage: openai.Usage{PromptTokens: 100, TotalTokens: 100}}
+	for _, text := range texts {
+		vector := make([]float64, len(keywords))
+		for i, keyword := range keywords {
+			vector[i] = float64(strings.Count(strings.ToLower(text), keyword))
+		}
+		embeddings.Vectors = append(embeddings.Vectors, vector)
+	}
+	return embeddings, nil
+}
+
+// TestClusterClaimsEmbeddings verifies clustering by embeddings and that their usage is counted.
+func TestClusterClaimsEmbeddings(t *testing.T) {
+	aggregatedClaims := clusterTestAggregation()
+	if err := ClusterClaims(aggregatedClaims, 0.99, fakeEmbedder{}, DefaultPriceTable); err != nil {
+		t.Fatalf("Error clustering claims: %v", err)
+	}
+
+	if len(aggregatedClaims.Clusters) != 3 || len(aggregatedClaims.Clusters[0].Members) != 3 {
+		t.Errorf("Expected the 3 Eiffel Tower claims to form the first of 3 clusters, got %+v", aggregatedClaims.Clusters)
+	}
+	if aggregatedClaims.Usage.Requests != 1 || aggregatedClaims.Usage.TotalTokens != 100 || aggregatedClaims.Usage.EstimatedCost <= 0 {
+		t.Errorf("Expected the embedding request to be counted, got %+v", aggregatedClaims.Usage)
+	}
+}
+
+// shortEmbedder returns one vector fewer than it was given texts.
+type shortEmbedder struct{}
+
+func (shortEmbedder) Embed(texts []string) (*openai.Embeddings, error) {
+	embeddings, err := fakeEmbedder{}.Embed(texts)
+	if err != nil {
+		return nil, err
+	}
+	embeddings.Vectors = embeddings.Vectors[:len(texts)-1]
+	return embeddings, nil
+}
+
+// TestClusterClaimsEmbeddingCount verifies that an embedder returning the wrong number of vectors is reported.
+func TestClusterClaimsEmbeddingCount(t *testing.T) {
+	if err := ClusterClaims(clusterTestAggregation(), 0, shortEmbedder{}, DefaultPriceTable); err == nil {
+		t.Error("Expected an error for a missing embedding")
+	}
+}
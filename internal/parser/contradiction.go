@@ -0,0 +1,186 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxContradictionPairs is how many candidate pairs of claims are compared when no limit is configured.
+const DefaultMaxContradictionPairs = 200
+
+// contradictionCandidateThreshold is the minimum word similarity for claims of different clusters to be
+// compared, so that only claims about the same subject are sent to the LLM.
+const contradictionCandidateThreshold = 0.3
+
+// Contradiction is a pair of claims from different pages that make incompatible statements.
+type Contradiction struct {
+	First       ContradictionSide `json:"first"`
+	Second      ContradictionSide `json:"second"`
+	Explanation string            `json:"explanation"`
+}
+
+// ContradictionSide is one of the conflicting claims, with the page that makes it and its sources.
+type ContradictionSide struct {
+	Page    string   `json:"page"`
+	Claim   string   `json:"claim"`
+	Sources []string `json:"sources"`
+}
+
+// claimRef points at a claim of an aggregation.
+type claimRef struct {
+	page, claim int
+}
+
+// contradictionCandidates pairs claims from different pages that are about the same subject: claims
+// of the same cluster with different wording, and sufficiently similar claims of different clusters.
+// The most similar pairs come first, and at most maxPairs are returned.
+func contradictionCandidates(aggregatedClaims *AggregatedClaims, maxPairs int) [][2]claimRef {
+	var refs []claimRef
+	var terms []map[string]bool
+	for p, page := range aggregatedClaims.AllClaims {
+		for c, claim := range page.Claims {
+			refs = append(refs, claimRef{page: p, claim: c})
+			terms = append(terms, claimTerms(claim.Claim))
+		}
+	}
+
+	type candidate struct {
+		pair       [2]claimRef
+		similarity float64
+	}
+	var candidates []candidate
+	for i := range refs {
+		a := aggregatedClaims.AllClaims[refs[i].page].Claims[refs[i].claim]
+		for j := i + 1; j < len(refs); j++ {
+			if aggregatedClaims.AllClaims[refs[i].page].Page == aggregatedClaims.AllClaims[refs[j].page].Page {
+				continue
+			}
+			b := aggregatedClaims.AllClaims[refs[j].page].Claims[refs[j].claim]
+			if strings.Join(quoteTokens(a.Claim), " ") == strings.Join(quoteTokens(b.Claim), " ") {
+				continue
+			}
+			similarity := termSimilarity(terms[i], terms[j])
+			sameCluster := a.ClusterID != 0 && a.ClusterID == b.ClusterID
+			if sameCluster || similarity >= contradictionCandidateThreshold {
+				candidates = append(candidates, candidate{pair: [2]claimRef{refs[i], refs[j]}, similarity: similarity})
+			}
+		}
+	}
+
+	// A stable sort keeps the aggregation order between equally similar pairs
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].similarity > candidates[j].similarity
+	})
+	if len(candidates) > maxPairs {
+		candidates = candidates[:maxPairs]
+	}
+
+	pairs := make([][2]claimRef, len(candidates))
+	for i, c := range candidates {
+		pairs[i] = c.pair
+	}
+	return pairs
+}
+
+// findContradictions asks the LLM to compare every candidate pair of claims and returns the pairs it
+// finds incompatible, in candidate order. Its usage is added to the aggregation, pairs that could not be
+// compared are reported in its errors, and no pairs are sent once the budget is exhausted, which is reported
+// in its errors along with the number of pairs left uncompared.
+func (s *scanState) findContradictions(aggregatedClaims *AggregatedClaims, maxPairs int) []Contradiction {
+	pairs := contradictionCandidates(aggregatedClaims, maxPairs)
+	results := make([]*Contradiction, len(pairs))
+	errs := make([]error, len(pairs))
+	skipped := make([]string, len(pairs))
+
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < s.o.llmConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				s.mu.Lock()
+				reason := s.o.budget.exhausted(0, aggregatedClaims.Usage, time.Since(s.started))
+				s.mu.Unlock()
+				if reason != "" {
+					skipped[i] = reason
+					continue
+				}
+				results[i], errs[i] = s.compareClaims(aggregatedClaims, pairs[i])
+			}
+		}()
+	}
+	for i := range pairs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	contradictions := []Contradiction{}
+	uncompared, budgetReason := 0, ""
+	for i, result := range results {
+		if skipped[i] != "" {
+			uncompared++
+			budgetReason = skipped[i]
+		}
+		if errs[i] != nil {
+			first, second := contradictionSide(aggregatedClaims, pairs[i][0]), contradictionSide(aggregatedClaims, pairs[i][1])
+			aggregatedClaims.Errors = append(aggregatedClaims.Errors, fmt.Sprintf("Error comparing claims of %s and %s: %v", first.Page, second.Page, errs[i]))
+		}
+		if result != nil {
+			contradictions = append(contradictions, *result)
+		}
+	}
+	if uncompared > 0 {
+		aggregatedClaims.Errors = append(aggregatedClaims.Errors, fmt.Sprintf("Error comparing claims: %d of %d pairs were not compared: %s", uncompared, len(pairs), budgetReason))
+	}
+	return contradictions
+}
+
+// compareClaims asks the LLM whether two claims contradict each other, returning the contradiction if they do.
+func (s *scanState) compareClaims(aggregatedClaims *AggregatedClaims, pair [2]claimRef) (*Contradiction, error) {
+	first := contradictionSide(aggregatedClaims, pair[0])
+	second := contradictionSide(aggregatedClaims, pair[1])
+	prompt := fmt.Sprintf(`
+		You are a fact checker that compares two statements taken from different articles.
+		Decide whether the statements are incompatible, meaning they cannot both be true, for example because they give
+		different dates, figures or outcomes for the same subject. Statements about different subjects, or that differ
+		only in wording or level of detail, are not contradictory.
+		DO NOT wrap response with Markdown code-block formatting.
+		Respond only with a JSON object formatted as follows:
+		{"contradicts": true, "explanation": "One sentence naming the incompatible detail."}
+		Statement 1: "%s"
+		Statement 2: "%s"
+	`, first.Claim, second.Claim)
+
+	completion, err := s.o.client.Complete(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare claims: %v", err)
+	}
+	s.mu.Lock()
+	aggregatedClaims.Usage.addCompletion(completion, s.o.prices)
+	s.mu.Unlock()
+
+	var verdict struct {
+		Contradicts bool   `json:"contradicts"`
+		Explanation string `json:"explanation"`
+	}
+	if err := json.Unmarshal([]byte(completion.Content), &verdict); err != nil {
+		return nil, fmt.Errorf("failed to parse comparison as JSON: %v", err)
+	}
+	if !verdict.Contradicts {
+		return nil, nil
+	}
+	return &Contradiction{First: first, Second: second, Explanation: verdict.Explanation}, nil
+}
+
+// contradictionSide describes a claim of the aggregation for a contradiction report.
+func contradictionSide(aggregatedClaims *AggregatedClaims, ref claimRef) ContradictionSide {
+	page := aggregatedClaims.AllClaims[ref.page]
+	claim := page.Claims[ref.claim]
+	return ContradictionSide{Page: page.Page, Claim: claim.Claim, Sources: claim.Source}
+}
@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"citation-scanner/pkg/openai"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// comparisonClient reports a contradiction for any pair of statements mentioning both given years.
+type comparisonClient struct {
+	mu      sync.Mutex
+	prompts int
+}
+
+func (c *comparisonClient) Complete(prompt string) (*openai.ChatCompletion, error) {
+	c.mu.Lock()
+	c.prompts++
+	c.mu.Unlock()
+
+	content := `{"contradicts": false, "explanation": ""}`
+	if strings.Contains(prompt, "1889") && strings.Contains(prompt, "1887") {
+		content = `{"contradicts": true, "explanation": "The statements give different completion years."}`
+	}
+	return &openai.ChatCompletion{Content: content, Model: "gpt-4o", Usage: openai.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}}, nil
+}
+
+// TestFindContradictions verifies that only incompatible claims about the same subject are reported, with their sources.
+func TestFindContradictions(t *testing.T) {
+	aggregatedClaims := &AggregatedClaims{
+		RootPage: "root",
+		AllClaims: []ParsedClaims{
+			{Page: "root", Claims: []Claim{
+				{Claim: "The Eiffel Tower was completed in 1889.", Source: []string{"history"}},
+				{Claim: "The Louvre opened in 1793.", Source: []string{}},
+			}},
+			{Page: "history", Claims: []Claim{
+				{Claim: "The Eiffel Tower was completed in 1887.", Source: []string{"archive"}},
+				{Claim: "The Eiffel Tower was completed in 1889.", Source: []string{}},
+				{Claim: "The Louvre museum opened to the public in 1793.", Source: []string{}},
+			}},
+		},
+	}
+	if err := ClusterClaims(aggregatedClaims, 0, nil, DefaultPriceTable); err != nil {
+		t.Fatalf("Error clustering claims: %v", err)
+	}
+
+	client := &comparisonClient{}
+	scan := newScanState(&Options{client: client, prices: DefaultPriceTable, llmConcurrency: 2})
+	contradictions := scan.findContradictions(aggregatedClaims, DefaultMaxContradictionPairs)
+
+	// Identical claims, claims on the same page and claims about other subjects are never compared
+	if client.prompts != 2 {
+		t.Errorf("Expected 2 pairs to be compared, got %d", client.prompts)
+	}
+	if len(contradictions) != 1 {
+		t.Fatalf("Expected 1 contradiction, got %d: %+v", len(contradictions), contradictions)
+	}
+	c := contradictions[0]
+	if c.First.Page != "root" || c.Second.Page != "history" || !strings.Contains(c.Second.Claim, "1887") || c.Second.Sources[0] != "archive" {
+		t.Errorf("Unexpected contradiction: %+v", c)
+	}
+	if aggregatedClaims.Usage.Requests != 2 {
+		t.Errorf("Expected the comparisons to be counted in the usage, got %+v", aggregatedClaims.Usage)
+	}
+
+	// The limit caps how many pairs are sent
+	if pairs := contradictionCandidates(aggregatedClaims, 1); len(pairs) != 1 {
+		t.Errorf("Expected the candidates to be limited to 1 pair, got %d", len(pairs))
+	}
+}
+
+// TestFindContradictionsBudget verifies that pairs left uncompared once the budget runs out are reported.
+func TestFindContradictionsBudget(t *testing.T) {
+	aggregatedClaims := &AggregatedClaims{
+		RootPage: "root",
+		AllClaims: []ParsedClaims{
+			{Page: "root", Claims: []Claim{{Claim: "The Eiffel Tower was completed in 1889.", Source: []string{"history"}}}},
+			{Page: "history", Claims: []Claim{
+				{Claim: "The Eiffel Tower was completed in 1887.", Source: []string{}},
+				{Claim: "The Eiffel Tower was completed in 1889 for the fair.", Source: []string{}},
+			}},
+		},
+	}
+
+	client := &comparisonClient{}
+	scan := newScanState(&Options{client: client, prices: DefaultPriceTable, llmConcurrency: 1, budget: Budget{MaxTokens: 10}})
+	scan.findContradictions(aggregatedClaims, DefaultMaxContradictionPairs)
+
+	if client.prompts != 1 {
+		t.Errorf("Expected 1 pair to be compared before the budget ran out, got %d", client.prompts)
+	}
+	if len(aggregatedClaims.Errors) != 1 || !strings.Contains(aggregatedClaims.Errors[0], "1 of 2 pairs were not compared: budget exhausted") {
+		t.Errorf("Expected the uncompared pair to be reported, got %v", aggregatedClaims.Errors)
+	}
+}
+
+// malformedClient answers every prompt with a response that is not JSON.
+type malformedClient struct{}
+
+func (malformedClient) Complete(prompt string) (*openai.ChatCompletion, error) {
+	return &openai.ChatCompletion{Content: "The statements look compatible to me.", Model: "gpt-4o"}, nil
+}
+
+// TestFindContradictionsErrors verifies that comparisons the LLM fails to answer are reported as errors rather than as compatible claims.
+func TestFindContradictionsErrors(t *testing.T) {
+	aggregatedClaims := &AggregatedClaims{
+		RootPage: "root",
+		AllClaims: []ParsedClaims{
+			{Page: "root", Claims: []Claim{{Claim: "The Eiffel Tower was completed in 1889.", Source: []string{}}}},
+			{Page: "history", Claims: []Claim{{Claim: "The Eiffel Tower was completed in 1887.", Source: []string{}}}},
+		},
+	}
+
+	scan := newScanState(&Options{client: malformedClient{}, prices: DefaultPriceTable, llmConcurrency: 1})
+	contradictions := scan.findContradictions(aggregatedClaims, DefaultMaxContradictionPairs)
+	if len(contradictions) != 0 {
+		t.Errorf("Expected no contradictions, got %+v", contradictions)
+	}
+	if len(aggregatedClaims.Errors) != 1 || !strings.Contains(aggregatedClaims.Errors[0], "root and history") {
+		t.Errorf("Expected the failed comparison to be reported, got %v", aggregatedClaims.Errors)
+	}
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// FlagUnsourced marks a claim that has no source, neither a URL nor a reference list entry.
+	FlagUnsourced = "unsourced"
+	// FlagCitationNeeded marks a claim that carries a maintenance template such as "[citation needed]" or "[who?]".
+	FlagCitationNeeded = "citation_needed"
+	// FlagWeaselWords marks a claim that attributes itself vaguely, such as "some experts say".
+	FlagWeaselWords = "weasel_words"
+)
+
+// citationNeededTemplate matches the inline maintenance templates editors add to claims that lack a proper source.
+var citationNeededTemplate = regexp.MustCompile(`(?i)\[\s*(citation needed|who\?|whom\?|by whom\?|according to whom\?|which\?|when\?|where\?|clarification needed|dubious(?:\s*[–-]\s*discuss)?|verification needed|failed verification|better source needed|unreliable source\?|original research\?|page needed|vague|weasel words?)\s*\]`)
+
+// weaselPhrase matches vague attributions that stand in for a source.
+var weaselPhrase = regexp.MustCompile(`(?i)\b(` +
+	`(some|many|most|several|certain) (experts|people|scientists|researchers|critics|observers|scholars|historians|commentators|analysts)( have)? (say|said|claim|claimed|argue|argued|believe|believed|think|thought|suggest|suggested|agree|agreed)|` +
+	`it (is|has been|was) (widely|often|generally|commonly|sometimes|frequently) (said|believed|thought|considered|accepted|claimed|argued|reported|held)|` +
+	`(is|are|was|were) (widely|often|generally|commonly) (considered|regarded|believed|seen|thought)|` +
+	`(studies|research|experts|evidence) (show|shows|have shown|has shown|suggest|suggests)|` +
+	`(critics|experts|scientists) (say|argue|claim)|` +
+	`according to (some|many|experts)` +
+	`)\b`)
+
+// CitationCoverage reports how many of a page's claims are backed by a source, and the signs of
+// missing sources found on the page.
+type CitationCoverage struct {
+	Claims          int            `json:"claims"`
+	SourcedClaims   int            `json:"sourced_claims"`
+	UnsourcedClaims int            `json:"unsourced_claims"`
+	WeaselClaims    int            `json:"weasel_claims"`
+	Percent         float64        `json:"percent"`
+	Templates       map[string]int `json:"templates,omitempty"`
+}
+
+// analyzeCoverage flags unsourced, "citation needed" and vaguely attributed claims, and counts the
+// maintenance templates found anywhere in the page text.
+func analyzeCoverage(claims []Claim, pageText string) *CitationCoverage {
+	coverage := &CitationCoverage{Claims: len(claims)}
+
+	for _, match := range citationNeededTemplate.FindAllStringSubmatch(pageText, -1) {
+		if coverage.Templates == nil {
+			coverage.Templates = make(map[string]int)
+		}
+		coverage.Templates[strings.ToLower(match[1])]++
+	}
+
+	for i := range claims {
+		claim := &claims[i]
+		if len(claim.Source) > 0 || claim.hasPrintSource() {
+			coverage.SourcedClaims++
+		} else {
+			coverage.UnsourcedClaims++
+			claim.addFlag(FlagUnsourced)
+		}
+		if citationNeededTemplate.MatchString(claim.Claim) {
+			claim.addFlag(FlagCitationNeeded)
+		}
+		if weaselPhrase.MatchString(claim.Claim) {
+			coverage.WeaselClaims++
+			claim.addFlag(FlagWeaselWords)
+		}
+	}
+
+	if coverage.Claims > 0 {
+		coverage.Percent = 100 * float64(coverage.SourcedClaims) / float64(coverage.Claims)
+	}
+	return coverage
+}
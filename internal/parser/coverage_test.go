@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"testing"
+)
+
+// TestAnalyzeCoverage verifies the flags and counts produced for sourced, unsourced and vaguely attributed claims.
+func TestAnalyzeCoverage(t *testing.T) {
+	claims := []Claim{
+		{Claim: "Go was designed at Google.[1]", Source: []string{"https://example.org/go"}},
+		{Claim: "Some experts say Go is the fastest language.[who?]", Source: []string{}},
+		{Claim: "Go is widely regarded as simple.", Source: []string{}},
+		{Claim: "Go has garbage collection.[citation needed]", Source: []string{}},
+	}
+	pageText := "Some experts say Go is the fastest language.[who?] Go has garbage collection.[citation needed] Go is popular.[Citation Needed]"
+
+	coverage := analyzeCoverage(claims, pageText)
+
+	if coverage.Claims != 4 || coverage.SourcedClaims != 1 || coverage.UnsourcedClaims != 3 || coverage.Percent != 25 {
+		t.Errorf("Unexpected coverage counts: %+v", coverage)
+	}
+	if coverage.WeaselClaims != 2 {
+		t.Errorf("Expected 2 weasel claims, got %d", coverage.WeaselClaims)
+	}
+	if coverage.Templates["citation needed"] != 2 || coverage.Templates["who?"] != 1 {
+		t.Errorf("Expected 2 citation needed and 1 who? templates, got %v", coverage.Templates)
+	}
+
+	if len(claims[0].Flags) != 0 {
+		t.Errorf("Expected the sourced claim to have no flags, got %v", claims[0].Flags)
+	}
+	if !hasFlag(claims[1], FlagUnsourced) || !hasFlag(claims[1], FlagCitationNeeded) || !hasFlag(claims[1], FlagWeaselWords) {
+		t.Errorf("Expected the vague claim to be flagged as unsourced, citation needed and weasel words, got %v", claims[1].Flags)
+	}
+	if !hasFlag(claims[2], FlagWeaselWords) || hasFlag(claims[2], FlagCitationNeeded) {
+		t.Errorf("Expected only weasel words and unsourced flags, got %v", claims[2].Flags)
+	}
+	if !hasFlag(claims[3], FlagCitationNeeded) {
+		t.Errorf("Expected the citation needed flag, got %v", claims[3].Flags)
+	}
+}
+
+// TestParsePageClaimsCoverage verifies that the coverage of a page is part of its parsed claims.
+func TestParsePageClaimsCoverage(t *testing.T) {
+	parsedClaims, err := ParsePageClaims(rootFixtureURL, replayOptions(t)...)
+	if err != nil {
+		t.Fatalf("Error parsing claims: %v", err)
+	}
+
+	coverage := parsedClaims.Coverage
+	if coverage == nil {
+		t.Fatal("Expected coverage to be reported")
+	}
+	if coverage.Claims != 5 || coverage.SourcedClaims != 3 || coverage.Percent != 60 {
+		t.Errorf("Expected 3 of 5 claims to be sourced, counting the book without a URL, got %+v", coverage)
+	}
+	if coverage.Templates["citation needed"] != 1 {
+		t.Errorf("Expected 1 citation needed template on the page, got %v", coverage.Templates)
+	}
+}
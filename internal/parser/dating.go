@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"regexp"
+	"time"
+)
+
+const (
+	// FlagAnachronisticSource marks a claim citing a source published after the citing page was last updated.
+	FlagAnachronisticSource = "anachronistic_source"
+	// FlagStaleSource marks a time-sensitive claim backed by a source older than the staleness threshold.
+	FlagStaleSource = "stale_source"
+)
+
+// yearDuration is the length of a year used for staleness thresholds.
+const yearDuration = 365 * 24 * time.Hour
+
+// DefaultStalenessThresholds is how old a source of a time-sensitive claim may be, by claim type.
+// The "" entry applies to the claim types without their own threshold.
+var DefaultStalenessThresholds = map[string]time.Duration{
+	"":             5 * yearDuration,
+	ClaimStatistic: 2 * yearDuration,
+}
+
+// anachronismGrace absorbs dates that only give a day, or that were recorded in another time zone.
+const anachronismGrace = 24 * time.Hour
+
+// timeSensitive matches the words that make a claim about the present rather than a fixed point in time.
+var timeSensitive = regexp.MustCompile(`(?i)\b(current|currently|now|nowadays|today|at present|presently|to date|so far|latest|most recent|recently|this year|as of)\b`)
+
+// checkDates records the publication date of every source whose date is known, from the pages fetched
+// during the scan, and flags sources dated after their citing page and stale sources of time-sensitive claims.
+// A page is dated by its last modification, or its publication when it declares no modification, and
+// time-sensitive claims on undated pages are compared with now.
+func checkDates(pages []ParsedClaims, fetched map[string]*fetchedPage, thresholds map[string]time.Duration, now time.Time) {
+	published := make(map[string]time.Time)
+	for _, page := range pages {
+		if page.Published != nil {
+			published[page.Page] = *page.Published
+		}
+	}
+	for url, page := range fetched {
+		if !page.dates.Published.IsZero() {
+			published[url] = page.dates.Published
+		}
+	}
+
+	for p := range pages {
+		page := &pages[p]
+		var citing *time.Time
+		if page.Modified != nil {
+			citing = page.Modified
+		} else if page.Published != nil {
+			citing = page.Published
+		}
+
+		for c := range page.Claims {
+			claim := &page.Claims[c]
+			for _, source := range claim.Source {
+				date, ok := published[source]
+				if !ok {
+					continue
+				}
+				record := claim.sourceRecord(source)
+				record.Published = &date
+
+				if citing != nil && date.After(citing.Add(anachronismGrace)) {
+					claim.addFlag(FlagAnachronisticSource)
+				}
+				if !timeSensitive.MatchString(claim.Claim) {
+					continue
+				}
+				threshold, ok := thresholds[claim.Type]
+				if !ok {
+					threshold = thresholds[""]
+				}
+				reference := now
+				if citing != nil {
+					reference = *citing
+				}
+				if threshold > 0 && reference.Sub(date) > threshold {
+					claim.addFlag(FlagStaleSource)
+				}
+			}
+		}
+	}
+}
+
+// timePtr returns a pointer to t, or nil when t is zero so that unknown dates are omitted from JSON.
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
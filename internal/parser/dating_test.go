@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseAndAggregateClaimsDates verifies that page dates are extracted and that a source dated after its citing page is flagged.
+func TestParseAndAggregateClaimsDates(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+
+	aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, 1, replayOptions(t)...)
+	if err != nil {
+		t.Fatalf("Error aggregating claims: %v", err)
+	}
+
+	root := aggregatedClaims.AllClaims[0]
+	if root.Published == nil || root.Modified == nil || root.Modified.Year() != 2018 {
+		t.Fatalf("Expected the root page to be published in 2015 and modified in 2018, got %v and %v", root.Published, root.Modified)
+	}
+	for _, claim := range root.Claims {
+		// The survey was published in 2021, after the root page was last modified
+		anachronistic := len(claim.Source) > 0 && claim.Source[0] == "https://example.net/survey"
+		if hasFlag(claim, FlagAnachronisticSource) != anachronistic {
+			t.Errorf("Expected claim %q to be flagged as anachronistic: %v, got flags %v", claim.Claim, anachronistic, claim.Flags)
+		}
+		if len(claim.Source) > 0 && claim.sourceRecord(claim.Source[0]).Published == nil {
+			t.Errorf("Expected the publication date of %s to be recorded", claim.Source[0])
+		}
+	}
+}
+
+// TestCheckDatesStaleness verifies that only time-sensitive claims are checked for stale sources, using the threshold of their type.
+func TestCheckDatesStaleness(t *testing.T) {
+	date := func(year int) *time.Time {
+		d := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	pages := []ParsedClaims{
+		{Page: "https://example.com/article", Published: date(2024), Claims: []Claim{
+			{Claim: "Unemployment is currently 4%.", Type: ClaimStatistic, Source: []string{"https://example.com/2021"}},
+			{Claim: "The bridge is currently the longest in Europe.", Type: ClaimEvent, Source: []string{"https://example.com/2021"}},
+			{Claim: "The bridge is currently the longest in Europe.", Type: ClaimEvent, Source: []string{"https://example.com/2010"}},
+			{Claim: "Unemployment was 4% in 2010.", Type: ClaimStatistic, Source: []string{"https://example.com/2010"}},
+		}},
+		{Page: "https://example.com/2021", Published: date(2021), Claims: []Claim{}},
+		{Page: "https://example.com/2010", Published: date(2010), Claims: []Claim{}},
+	}
+
+	checkDates(pages, nil, DefaultStalenessThresholds, time.Now())
+	for i, want := range []bool{true, false, true, false} {
+		claim := pages[0].Claims[i]
+		if hasFlag(claim, FlagStaleSource) != want {
+			t.Errorf("Expected claim %d %q citing %s to be stale: %v, got flags %v", i, claim.Claim, claim.Source[0], want, claim.Flags)
+		}
+		if hasFlag(claim, FlagAnachronisticSource) {
+			t.Errorf("Expected claim %d not to be anachronistic, got flags %v", i, claim.Flags)
+		}
+	}
+
+	pages[0].Claims[0].Flags = nil
+	checkDates(pages, nil, map[string]time.Duration{"": 5 * yearDuration}, time.Now())
+	if hasFlag(pages[0].Claims[0], FlagStaleSource) {
+		t.Errorf("Expected a statistic to use the default threshold when its type has none, got flags %v", pages[0].Claims[0].Flags)
+	}
+}
@@ -0,0 +1,134 @@
+package parser
+
+// Cycle is a chain of pages that cite each other in a loop. Pages starts and ends with the same page.
+type Cycle struct {
+	Pages []string    `json:"pages"`
+	Links []CycleLink `json:"links"`
+}
+
+// CycleLink is a citation from one page of a cycle to the next, along with the claims that make it.
+type CycleLink struct {
+	From   string   `json:"from"`
+	To     string   `json:"to"`
+	Claims []string `json:"claims"`
+}
+
+// citationGraph is a directed graph of scanned pages where an edge means that a claim on one page cites the other.
+type citationGraph struct {
+	pages  []string
+	edges  map[string][]string
+	claims map[[2]string][]string
+}
+
+// buildCitationGraph builds the graph of citations between the scanned pages, keeping the order in which they were aggregated.
+// Citations of pages that were not scanned are left out, since they cannot be part of a cycle.
+func buildCitationGraph(pages []ParsedClaims) *citationGraph {
+	g := &citationGraph{
+		edges:  make(map[string][]string),
+		claims: make(map[[2]string][]string),
+	}
+	scanned := make(map[string]bool, len(pages))
+	for _, page := range pages {
+		if !scanned[page.Page] {
+			scanned[page.Page] = true
+			g.pages = append(g.pages, page.Page)
+		}
+	}
+
+	for _, page := range pages {
+		for _, claim := range page.Claims {
+			for _, source := range claim.Source {
+				if !scanned[source] {
+					continue
+				}
+				edge := [2]string{page.Page, source}
+				if _, exists := g.claims[edge]; !exists {
+					g.edges[page.Page] = append(g.edges[page.Page], source)
+				}
+				g.claims[edge] = append(g.claims[edge], claim.Claim)
+			}
+		}
+	}
+	return g
+}
+
+// maxCycles bounds the number of loops reported, as densely connected pages can form exponentially many.
+const maxCycles = 1000
+
+// cycles finds every elementary citation loop of the graph with Johnson's algorithm, up to maxCycles. Each loop is
+// reported once, starting from the page of the loop that was aggregated first.
+func (g *citationGraph) cycles() []Cycle {
+	index := make(map[string]int, len(g.pages))
+	for i, page := range g.pages {
+		index[page] = i
+	}
+	cycles := []Cycle{}
+
+	for s, start := range g.pages {
+		// Only look for the loops through start among the pages aggregated after it, as the loops through
+		// earlier pages were all found already
+		blocked := make(map[string]bool)
+		blockedBy := make(map[string]map[string]bool)
+		var stack []string
+
+		var unblock func(page string)
+		unblock = func(page string) {
+			blocked[page] = false
+			for other := range blockedBy[page] {
+				delete(blockedBy[page], other)
+				if blocked[other] {
+					unblock(other)
+				}
+			}
+		}
+
+		var circuit func(page string) bool
+		circuit = func(page string) bool {
+			found := false
+			stack = append(stack, page)
+			blocked[page] = true
+			for _, next := range g.edges[page] {
+				switch {
+				case len(cycles) >= maxCycles || index[next] < s:
+					// Past the limit, or a page whose loops were all found already
+				case next == start:
+					cycles = append(cycles, g.cycle(append(append([]string{}, stack...), start)))
+					found = true
+				case !blocked[next] && circuit(next):
+					found = true
+				}
+			}
+			if found {
+				unblock(page)
+			} else {
+				// Keep the page blocked until a page it leads to is unblocked, since no loop can go through it before then
+				for _, next := range g.edges[page] {
+					if index[next] >= s {
+						if blockedBy[next] == nil {
+							blockedBy[next] = make(map[string]bool)
+						}
+						blockedBy[next][page] = true
+					}
+				}
+			}
+			stack = stack[:len(stack)-1]
+			return found
+		}
+
+		circuit(start)
+		if len(cycles) >= maxCycles {
+			break
+		}
+	}
+	return cycles
+}
+
+// cycle describes the loop through the given chain of pages together with the claims linking them.
+func (g *citationGraph) cycle(chain []string) Cycle {
+	c := Cycle{Pages: chain}
+	for i := 0; i+1 < len(chain); i++ {
+		edge := [2]string{chain[i], chain[i+1]}
+		c.Links = append(c.Links, CycleLink{From: edge[0], To: edge[1], Claims: g.claims[edge]})
+	}
+	return c
+}
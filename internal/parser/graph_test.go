@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestCitationGraphCycles verifies that loops between pages are found with the claims that link them.
+func TestCitationGraphCycles(t *testing.T) {
+	pages := []ParsedClaims{
+		{Page: "a", Claims: []Claim{{Claim: "a cites b", Source: []string{"b"}}}},
+		{Page: "b", Claims: []Claim{{Claim: "b cites c", Source: []string{"c", "unscanned"}}}},
+		{Page: "c", Claims: []Claim{{Claim: "c cites a", Source: []string{"a"}}, {Claim: "c cites itself", Source: []string{"c"}}}},
+		{Page: "d", Claims: []Claim{{Claim: "d cites a", Source: []string{"a"}}}},
+	}
+
+	cycles := buildCitationGraph(pages).cycles()
+	if len(cycles) != 2 {
+		t.Fatalf("Expected 2 cycles, got %d: %+v", len(cycles), cycles)
+	}
+
+	if want := []string{"a", "b", "c", "a"}; !reflect.DeepEqual(cycles[0].Pages, want) {
+		t.Errorf("Expected cycle %v, got %v", want, cycles[0].Pages)
+	}
+	if len(cycles[0].Links) != 3 || cycles[0].Links[2].Claims[0] != "c cites a" {
+		t.Errorf("Expected the closing link to carry the claim \"c cites a\", got %+v", cycles[0].Links)
+	}
+	if want := []string{"c", "c"}; !reflect.DeepEqual(cycles[1].Pages, want) {
+		t.Errorf("Expected self-citation cycle %v, got %v", want, cycles[1].Pages)
+	}
+}
+
+// TestCitationGraphCyclesElementary verifies that every elementary loop is found, including loops that share pages.
+func TestCitationGraphCyclesElementary(t *testing.T) {
+	pages := []ParsedClaims{
+		{Page: "a", Claims: []Claim{{Claim: "a cites b and c", Source: []string{"b", "c"}}}},
+		{Page: "b", Claims: []Claim{{Claim: "b cites c", Source: []string{"c"}}}},
+		{Page: "c", Claims: []Claim{{Claim: "c cites a", Source: []string{"a"}}}},
+	}
+
+	var got [][]string
+	for _, cycle := range buildCitationGraph(pages).cycles() {
+		got = append(got, cycle.Pages)
+	}
+	if want := [][]string{{"a", "b", "c", "a"}, {"a", "c", "a"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected cycles %v, got %v", want, got)
+	}
+}
+
+// TestParseAndAggregateClaimsCycles verifies that cycles between scanned pages are returned with the aggregation.
+func TestParseAndAggregateClaimsCycles(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+
+	aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, 1, replayOptions(t)...)
+	if err != nil {
+		t.Fatalf("Error parsing and aggregating claims: %v", err)
+	}
+
+	want := []string{"https://example.org/papers/fukuyama", "https://example.net/survey", "https://example.org/papers/fukuyama"}
+	if len(aggregatedClaims.Cycles) != 1 || !reflect.DeepEqual(aggregatedClaims.Cycles[0].Pages, want) {
+		t.Errorf("Expected the cycle %v, got %+v", want, aggregatedClaims.Cycles)
+	}
+}
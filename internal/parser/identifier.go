@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"citation-scanner/internal/retraction"
+	"citation-scanner/pkg/bibliography"
+	"citation-scanner/pkg/identifier"
+)
+
+// identifySources records the DOI, PubMed ID, arXiv ID or ISBN of every source of the claims, read from the source
+// itself when it is a bare identifier or a resolver link, or else from its entry in the page's reference list.
+func identifySources(claims []Claim) {
+	for i := range claims {
+		claim := &claims[i]
+		for _, source := range claim.Source {
+			if id, ok := identifier.FromURL(source); ok {
+				claim.sourceRecord(source).Identifier = &id
+			}
+		}
+		for j := range claim.Details {
+			record := &claim.Details[j]
+			if record.Identifier == nil && record.Bibliography != nil {
+				record.Identifier = bibliographyIdentifier(record.Bibliography)
+			}
+		}
+	}
+}
+
+// identifierOf returns the identifier recorded for a source of the claim, if any.
+func identifierOf(claim Claim, source string) *identifier.Identifier {
+	for _, record := range claim.Details {
+		if record.URL == source && record.Identifier != nil {
+			return record.Identifier
+		}
+	}
+	return nil
+}
+
+// bibliographyIdentifier returns the most specific identifier of a bibliographic record, or nil if it has none.
+func bibliographyIdentifier(item *bibliography.Item) *identifier.Identifier {
+	switch {
+	case item.DOI != "":
+		return &identifier.Identifier{Kind: identifier.DOI, Value: item.DOI}
+	case item.PMID != "":
+		return &identifier.Identifier{Kind: identifier.PMID, Value: item.PMID}
+	case item.ISBN != "":
+		return &identifier.Identifier{Kind: identifier.ISBN, Value: item.ISBN}
+	}
+	return nil
+}
+
+// retractionIdentifiers converts an identifier to the identifiers the retraction database is indexed by.
+func retractionIdentifiers(id *identifier.Identifier) retraction.Identifiers {
+	var ids retraction.Identifiers
+	if id == nil {
+		return ids
+	}
+	switch id.Kind {
+	case identifier.DOI:
+		ids.DOI = id.Value
+	case identifier.PMID:
+		ids.PMID = id.Value
+	}
+	return ids
+}
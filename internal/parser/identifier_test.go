@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"citation-scanner/pkg/bibliography"
+	"citation-scanner/pkg/identifier"
+	"citation-scanner/pkg/openai"
+	"testing"
+)
+
+// TestIdentifySources verifies that identifiers are read from the sources and from the records of sources without a URL.
+func TestIdentifySources(t *testing.T) {
+	claims := []Claim{{
+		Claim:  "Trust lowers costs.[1][2][3]",
+		Source: []string{"https://doi.org/10.1000/ABC", "arXiv:2101.00001", "https://example.org/page"},
+		Details: []SourceRecord{{
+			Bibliography: &bibliography.Item{ID: "cite_note-3", Type: bibliography.TypeBook, ISBN: "9780684832838"},
+		}},
+	}}
+
+	identifySources(claims)
+	want := map[string]string{
+		"https://doi.org/10.1000/ABC": "doi:10.1000/abc",
+		"arXiv:2101.00001":            "arxiv:2101.00001",
+		"":                            "isbn:9780684832838",
+	}
+	for _, record := range claims[0].Details {
+		if record.Identifier == nil {
+			if want[record.URL] != "" || record.URL == "" {
+				t.Errorf("Expected an identifier for %q, got none", record.URL)
+			}
+			continue
+		}
+		if record.Identifier.String() != want[record.URL] {
+			t.Errorf("Expected %s for %q, got %s", want[record.URL], record.URL, record.Identifier)
+		}
+	}
+	if len(claims[0].Details) != 3 {
+		t.Errorf("Expected records only for the identified sources, got %+v", claims[0].Details)
+	}
+}
+
+// TestFetchPageIdentifier verifies that a source given as a bare identifier is fetched from its landing page through the configured resolver.
+func TestFetchPageIdentifier(t *testing.T) {
+	var fetched []string
+	o := applyOptions(
+		WithFetcher(func(url string) (string, error) {
+			fetched = append(fetched, url)
+			return "<html></html>", nil
+		}),
+		WithIdentifierResolver(identifier.NewResolver(identifier.WithEndpoint(identifier.DOI, "https://resolver.example.org/{id}"))),
+	)
+
+	if _, _, _, err := fetchPage("doi:10.5555/jsc.1995.0042", archiveHint{}, o); err != nil {
+		t.Fatalf("Error fetching page: %v", err)
+	}
+	if _, _, _, err := fetchPage("https://example.org/page", archiveHint{}, o); err != nil {
+		t.Fatalf("Error fetching page: %v", err)
+	}
+	if len(fetched) != 2 || fetched[0] != "https://resolver.example.org/10.5555/jsc.1995.0042" || fetched[1] != "https://example.org/page" {
+		t.Errorf("Expected the DOI to be fetched from the resolver and the URL as it is, got %v", fetched)
+	}
+}
+
+// landingClient extracts a single claim citing the landing page's reference.
+type landingClient struct{}
+
+func (landingClient) Complete(prompt string) (*openai.ChatCompletion, error) {
+	content := `{"claims": [{"claim": "Trust predicts growth.[1]", "type": "causal", "sources": ["https://journal.example.org/papers/cited"]}]}`
+	return &openai.ChatCompletion{Content: content, Model: "gpt-4o"}, nil
+}
+
+// TestParsePageIdentifier verifies that a page given as a bare identifier keeps the identifier as its page, while
+// the links of its reference list are resolved against the landing page it was fetched from.
+func TestParsePageIdentifier(t *testing.T) {
+	page := `<html><body><p>Trust predicts growth.[1]</p><ol class="references"><li id="cite_note-1"><a href="/papers/cited">Cited paper</a></li></ol></body></html>`
+	parsedClaims, err := ParsePageClaims("doi:10.5555/jsc.1995.0042",
+		WithClient(landingClient{}),
+		WithFetcher(func(url string) (string, error) { return page, nil }),
+		WithIdentifierResolver(identifier.NewResolver(identifier.WithEndpoint(identifier.DOI, "https://journal.example.org/doi/{id}"))),
+	)
+	if err != nil {
+		t.Fatalf("Error parsing page: %v", err)
+	}
+
+	if parsedClaims.Page != "doi:10.5555/jsc.1995.0042" {
+		t.Errorf("Expected the page to keep its identifier, got %q", parsedClaims.Page)
+	}
+	if len(parsedClaims.Bibliography) != 1 || parsedClaims.Bibliography[0].URL != "https://journal.example.org/papers/cited" {
+		t.Errorf("Expected the reference to be resolved against the landing page, got %+v", parsedClaims.Bibliography)
+	}
+	if details := parsedClaims.Claims[0].Details; len(details) == 0 || details[0].Bibliography == nil {
+		t.Errorf("Expected the claim's source to get the reference's record, got %+v", details)
+	}
+}
@@ -0,0 +1,82 @@
+package parser
+
+// FlagLaunderedSources marks a claim whose sources trace back to fewer independent origins than it cites.
+const FlagLaunderedSources = "laundered_sources"
+
+// Laundering reports a claim whose apparently independent sources converge on a shared upstream origin.
+type Laundering struct {
+	Page               string         `json:"page"`
+	Claim              string         `json:"claim"`
+	Sources            int            `json:"sources"`
+	IndependentSources int            `json:"independent_sources"`
+	Origins            []SourceOrigin `json:"origins"`
+}
+
+// SourceOrigin is the upstream origin a cited source was traced to.
+type SourceOrigin struct {
+	Source string `json:"source"`
+	Origin string `json:"origin"`
+	Hops   int    `json:"hops"`
+}
+
+// detectLaundering traces every source of each claim with several sources to its origin, and reports
+// and flags the claims whose sources share origins. Sources that cannot be followed count as their own origin, and a
+// chain that stops at a source that was not scanned or could not be fetched ends at that source.
+func detectLaundering(aggregatedClaims *AggregatedClaims) []Laundering {
+	t := newProvenanceTracer(aggregatedClaims)
+	reports := []Laundering{}
+
+	for p := range aggregatedClaims.AllClaims {
+		page := &aggregatedClaims.AllClaims[p]
+		for c := range page.Claims {
+			claim := &page.Claims[c]
+			sources := uniqueStrings(claim.Source)
+			if len(sources) < 2 {
+				continue
+			}
+
+			origins := make([]SourceOrigin, 0, len(sources))
+			independent := make(map[string]bool)
+			for _, source := range sources {
+				chain := t.follow(*claim, source, map[string]bool{page.Page: true})
+				origin := SourceOrigin{Source: source, Origin: source}
+				if len(chain.hops) > 0 {
+					origin.Origin = chain.hops[len(chain.hops)-1].Page
+					origin.Hops = len(chain.hops)
+				}
+				if chain.reason == BreakNotScanned || chain.reason == BreakDeadLink {
+					// The fact continues past the last scanned page, so the unfollowed source is the furthest known origin
+					origin.Origin = chain.brokenAt
+					origin.Hops = len(chain.hops) + 1
+				}
+				origins = append(origins, origin)
+				independent[origin.Origin] = true
+			}
+
+			if len(independent) < len(sources) {
+				claim.addFlag(FlagLaunderedSources)
+				reports = append(reports, Laundering{
+					Page:               page.Page,
+					Claim:              claim.Claim,
+					Sources:            len(sources),
+					IndependentSources: len(independent),
+					Origins:            origins,
+				})
+			}
+		}
+	}
+	return reports
+}
+
+// uniqueStrings returns the distinct values of a slice in their original order.
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
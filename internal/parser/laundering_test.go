@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"testing"
+)
+
+// TestDetectLaundering verifies that sources converging on one origin are reported as a single independent source.
+func TestDetectLaundering(t *testing.T) {
+	aggregatedClaims := &AggregatedClaims{
+		RootPage: "root",
+		AllClaims: []ParsedClaims{
+			{Page: "root", Claims: []Claim{
+				{Claim: "Coffee drinkers live five years longer.", Source: []string{"news-a", "news-b", "study"}},
+				{Claim: "Tea is popular in Britain.", Source: []string{"survey-a", "survey-b"}},
+			}},
+			{Page: "news-a", Claims: []Claim{{Claim: "Coffee drinkers live five years longer, a blog reports.", Source: []string{"blog"}}}},
+			{Page: "news-b", Claims: []Claim{{Claim: "A blog says coffee drinkers live five years longer.", Source: []string{"blog"}}}},
+			{Page: "blog", Claims: []Claim{{Claim: "Coffee drinkers live five years longer.", Source: []string{}}}},
+			{Page: "study", Claims: []Claim{{Claim: "Coffee consumption was not associated with lifespan.", Source: []string{}}}},
+			{Page: "survey-a", Claims: []Claim{{Claim: "Tea is popular in Britain.", Source: []string{}}}},
+			{Page: "survey-b", Claims: []Claim{{Claim: "Tea is very popular in Britain.", Source: []string{}}}},
+		},
+	}
+
+	reports := detectLaundering(aggregatedClaims)
+	if len(reports) != 1 {
+		t.Fatalf("Expected 1 laundering report, got %d: %+v", len(reports), reports)
+	}
+
+	report := reports[0]
+	if report.Sources != 3 || report.IndependentSources != 2 {
+		t.Errorf("Expected 3 sources with 2 independent origins, got %d and %d", report.Sources, report.IndependentSources)
+	}
+	for _, origin := range report.Origins[:2] {
+		if origin.Origin != "blog" || origin.Hops != 2 {
+			t.Errorf("Expected %s to trace to the blog in 2 hops, got %+v", origin.Source, origin)
+		}
+	}
+	if !hasFlag(aggregatedClaims.AllClaims[0].Claims[0], FlagLaunderedSources) {
+		t.Error("Expected the laundered claim to be flagged")
+	}
+	if hasFlag(aggregatedClaims.AllClaims[0].Claims[1], FlagLaunderedSources) {
+		t.Error("Expected the claim with independent sources not to be flagged")
+	}
+}
+
+// TestDetectLaunderingUnscannedOrigin verifies that chains passing through one scanned page to different unscanned
+// sources are not taken to share that page as their origin.
+func TestDetectLaunderingUnscannedOrigin(t *testing.T) {
+	aggregatedClaims := &AggregatedClaims{
+		RootPage: "root",
+		AllClaims: []ParsedClaims{
+			{Page: "root", Claims: []Claim{
+				{Claim: "Coffee drinkers live five years longer.", Source: []string{"news-a", "news-b"}},
+			}},
+			{Page: "news-a", Claims: []Claim{{Claim: "Coffee drinkers live five years longer, a review finds.", Source: []string{"review"}}}},
+			{Page: "news-b", Claims: []Claim{{Claim: "Coffee drinkers live five years longer, says a review.", Source: []string{"review"}}}},
+			{Page: "review", Claims: []Claim{
+				{Claim: "Coffee drinkers live five years longer, a review finds.", Source: []string{"study-a"}},
+				{Claim: "Coffee drinkers live five years longer, says a review.", Source: []string{"study-b"}},
+			}},
+		},
+	}
+
+	reports := detectLaundering(aggregatedClaims)
+	if len(reports) != 0 {
+		t.Fatalf("Expected no laundering reports, got %+v", reports)
+	}
+}
@@ -0,0 +1,221 @@
+package parser
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// FlagNumericMismatch marks a claim whose figures differ from the ones in a source's supporting quote.
+const FlagNumericMismatch = "numeric_mismatch"
+
+// Kinds of quantities found in claims and sources.
+const (
+	QuantityNumber   = "number"
+	QuantityPercent  = "percent"
+	QuantityCurrency = "currency"
+	QuantityYear     = "year"
+	QuantityDate     = "date"
+)
+
+// Quantity is a figure found in a text, normalized so that different spellings of the same value compare equal.
+// Dates are stored as YYYYMMDD.
+type Quantity struct {
+	Text  string  `json:"text"`
+	Kind  string  `json:"kind"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit,omitempty"`
+}
+
+// NumericMismatch is a figure of a claim that matches none of the figures of the same kind in the source.
+type NumericMismatch struct {
+	Claim  Quantity   `json:"claim"`
+	Source []Quantity `json:"source"`
+	Reason string     `json:"reason"`
+}
+
+const months = `(january|february|march|april|may|june|july|august|september|october|november|december)`
+
+var (
+	dayMonthYear = regexp.MustCompile(`(?i)\b(\d{1,2})\s+` + months + `,?\s+(\d{4})\b`)
+	monthDayYear = regexp.MustCompile(`(?i)\b` + months + `\s+(\d{1,2}),?\s+(\d{4})\b`)
+	isoDate      = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+
+	quantityPattern = regexp.MustCompile(`(?i)([$€£¥])?\s?(\d{1,3}(?:,\d{3})+|\d+)(\.\d+)?(?:\s?(%|percent\b|per cent\b|thousand\b|million\b|billion\b|trillion\b|bn\b))?(?:\s?(km|kilometres|kilometers|metres|meters|feet|miles|kg|kilograms|tonnes|tons|lbs|pounds|°c|°f|degrees|hours|days|weeks|months|years|people|deaths|cases|users|dollars|euros)\b)?`)
+)
+
+var monthNumbers = map[string]int{
+	"january": 1, "february": 2, "march": 3, "april": 4, "may": 5, "june": 6,
+	"july": 7, "august": 8, "september": 9, "october": 10, "november": 11, "december": 12,
+}
+
+var scales = map[string]float64{
+	"thousand": 1e3, "million": 1e6, "billion": 1e9, "bn": 1e9, "trillion": 1e12,
+}
+
+var currencies = map[string]string{
+	"$": "USD", "dollars": "USD", "€": "EUR", "euros": "EUR", "£": "GBP", "¥": "JPY",
+}
+
+var units = map[string]string{
+	"km": "km", "kilometres": "km", "kilometers": "km", "metres": "m", "meters": "m", "feet": "ft", "miles": "mi",
+	"kg": "kg", "kilograms": "kg", "tonnes": "t", "tons": "t", "lbs": "lb", "pounds": "lb",
+	"°c": "°C", "°f": "°F", "degrees": "°",
+}
+
+// extractQuantities finds the dates, years, percentages, amounts of money and other figures in a text.
+// Reference markers such as "[12]" and numbers that are part of names such as "COVID-19" are ignored.
+func extractQuantities(text string) []Quantity {
+	text = referenceMarker.ReplaceAllStringFunc(text, blank)
+	var quantities []Quantity
+
+	// Take out dates first so that their days and years are not read as separate figures
+	addDate := func(match string, year, month, day int) {
+		quantities = append(quantities, Quantity{Text: match, Kind: QuantityDate, Value: float64(year*10000 + month*100 + day)})
+	}
+	text = dayMonthYear.ReplaceAllStringFunc(text, func(match string) string {
+		m := dayMonthYear.FindStringSubmatch(match)
+		day, _ := strconv.Atoi(m[1])
+		year, _ := strconv.Atoi(m[3])
+		addDate(match, year, monthNumbers[strings.ToLower(m[2])], day)
+		return blank(match)
+	})
+	text = monthDayYear.ReplaceAllStringFunc(text, func(match string) string {
+		m := monthDayYear.FindStringSubmatch(match)
+		day, _ := strconv.Atoi(m[2])
+		year, _ := strconv.Atoi(m[3])
+		addDate(match, year, monthNumbers[strings.ToLower(m[1])], day)
+		return blank(match)
+	})
+	text = isoDate.ReplaceAllStringFunc(text, func(match string) string {
+		m := isoDate.FindStringSubmatch(match)
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		addDate(match, year, month, day)
+		return blank(match)
+	})
+
+	for _, idx := range quantityPattern.FindAllStringSubmatchIndex(text, -1) {
+		start := idx[4]
+		if idx[2] >= 0 {
+			start = idx[2]
+		}
+		// Skip digits that continue a word, such as "COVID-19" or "A4"
+		if start > 0 {
+			previous := rune(text[start-1])
+			if unicode.IsLetter(previous) || previous == '-' || previous == '.' || previous == '/' {
+				continue
+			}
+		}
+
+		group := func(n int) string {
+			if idx[2*n] < 0 {
+				return ""
+			}
+			return strings.ToLower(text[idx[2*n]:idx[2*n+1]])
+		}
+		symbol, integer, fraction, suffix, unit := group(1), group(2), group(3), group(4), group(5)
+		value, err := strconv.ParseFloat(strings.ReplaceAll(integer, ",", "")+fraction, 64)
+		if err != nil {
+			continue
+		}
+
+		q := Quantity{Text: strings.TrimSpace(text[idx[0]:idx[1]]), Kind: QuantityNumber, Value: value}
+		switch suffix {
+		case "%", "percent", "per cent":
+			q.Kind = QuantityPercent
+		case "":
+		default:
+			q.Value *= scales[suffix]
+		}
+		if currency, ok := currencies[symbol]; ok {
+			q.Kind, q.Unit = QuantityCurrency, currency
+		} else if currency, ok := currencies[unit]; ok {
+			q.Kind, q.Unit = QuantityCurrency, currency
+		} else if normalized, ok := units[unit]; ok {
+			q.Unit = normalized
+		} else {
+			q.Unit = unit
+		}
+		if q.Kind == QuantityNumber && q.Unit == "" && suffix == "" && fraction == "" && !strings.Contains(integer, ",") && value >= 1000 && value <= 2100 {
+			q.Kind = QuantityYear
+		}
+		quantities = append(quantities, q)
+	}
+	return quantities
+}
+
+// compareQuantities checks every figure of a claim against the figures of the same kind in the
+// source text. Figures without a counterpart of their kind in the source are not reported.
+func compareQuantities(claim, source string) []NumericMismatch {
+	sourceQuantities := extractQuantities(source)
+	var mismatches []NumericMismatch
+	for _, q := range extractQuantities(claim) {
+		var candidates []Quantity
+		matched := false
+		for _, s := range sourceQuantities {
+			value, comparable := comparableValue(q, s)
+			if !comparable {
+				continue
+			}
+			candidates = append(candidates, s)
+			if quantitiesEqual(q, value) {
+				matched = true
+				break
+			}
+		}
+		if matched || len(candidates) == 0 {
+			continue
+		}
+
+		reason := "different value"
+		for _, s := range candidates {
+			if value, _ := comparableValue(q, s); differentScale(q.Value, value) {
+				reason = "different scale"
+				break
+			}
+		}
+		mismatches = append(mismatches, NumericMismatch{Claim: q, Source: candidates, Reason: reason})
+	}
+	return mismatches
+}
+
+// comparableValue returns the value of a source quantity in the terms of a claim quantity, and whether they can be compared at all.
+// Years are compared with the years of dates as well.
+func comparableValue(claim, source Quantity) (float64, bool) {
+	switch {
+	case claim.Kind == QuantityYear && source.Kind == QuantityDate:
+		return math.Floor(source.Value / 10000), true
+	case claim.Kind != source.Kind || claim.Unit != source.Unit:
+		return 0, false
+	}
+	return source.Value, true
+}
+
+// quantitiesEqual reports whether two values agree, allowing for rounding except for years and dates.
+func quantitiesEqual(claim Quantity, value float64) bool {
+	switch claim.Kind {
+	case QuantityYear, QuantityDate:
+		return claim.Value == value
+	case QuantityPercent:
+		return math.Abs(claim.Value-value) <= 0.5
+	}
+	return math.Abs(claim.Value-value) <= 0.05*math.Max(math.Abs(claim.Value), math.Abs(value))
+}
+
+// differentScale reports whether two values differ by about a factor of a thousand or more, as when "million" is confused with "billion".
+func differentScale(a, b float64) bool {
+	if a == 0 || b == 0 {
+		return false
+	}
+	ratio := math.Abs(math.Log10(math.Abs(a / b)))
+	return ratio > 2.9 && math.Abs(ratio-3*math.Round(ratio/3)) < 0.1
+}
+
+// blank replaces a match with spaces of the same length, keeping the offsets of the rest of the text.
+func blank(match string) string {
+	return strings.Repeat(" ", len(match))
+}
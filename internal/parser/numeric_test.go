@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"testing"
+)
+
+// TestExtractQuantities verifies that figures are found and normalized, and that markers and names are ignored.
+func TestExtractQuantities(t *testing.T) {
+	tests := []struct {
+		text string
+		want []Quantity
+	}{
+		{"In 2020, 64% of respondents[2] agreed.", []Quantity{
+			{Text: "2020", Kind: QuantityYear, Value: 2020},
+			{Text: "64%", Kind: QuantityPercent, Value: 64},
+		}},
+		{"The project cost $1.2 billion and 40 percent more than planned.", []Quantity{
+			{Text: "$1.2 billion", Kind: QuantityCurrency, Value: 1.2e9, Unit: "USD"},
+			{Text: "40 percent", Kind: QuantityPercent, Value: 40},
+		}},
+		{"The bridge, opened on 12 March 1932, is 1,149 metres long and 134 km from Sydney.", []Quantity{
+			{Text: "12 March 1932", Kind: QuantityDate, Value: 19320312},
+			{Text: "1,149 metres", Kind: QuantityNumber, Value: 1149, Unit: "m"},
+			{Text: "134 km", Kind: QuantityNumber, Value: 134, Unit: "km"},
+		}},
+		{"COVID-19 spread to 2.5 million people[citation needed].", []Quantity{
+			{Text: "2.5 million people", Kind: QuantityNumber, Value: 2.5e6, Unit: "people"},
+		}},
+	}
+
+	for _, tt := range tests {
+		got := extractQuantities(tt.text)
+		if len(got) != len(tt.want) {
+			t.Errorf("Expected %d quantities in %q, got %+v", len(tt.want), tt.text, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Expected quantity %d of %q to be %+v, got %+v", i, tt.text, tt.want[i], got[i])
+			}
+		}
+	}
+}
+
+// TestCompareQuantities verifies that misquoted figures are reported and rounded or equivalent ones are not.
+func TestCompareQuantities(t *testing.T) {
+	tests := []struct {
+		claim  string
+		source string
+		reason string
+	}{
+		{"40% of voters stayed home.", "Some 14% of voters stayed home.", "different value"},
+		{"The deal was worth $2 billion.", "The deal was worth $2 million.", "different scale"},
+		{"The treaty was signed in 1919.", "The treaty was signed on 28 June 1921.", "different value"},
+		{"About 1.2 million people attended.", "1,187,000 people attended the event.", ""},
+		{"In 2020, 64% of respondents agreed.", "In 2020, 64 per cent of those surveyed agreed.", ""},
+		{"The treaty was signed in 1919.", "The treaty was signed on June 28, 1919.", ""},
+		{"The tower is 324 metres tall.", "It drew 7 million visitors in 2015.", ""},
+	}
+
+	for _, tt := range tests {
+		mismatches := compareQuantities(tt.claim, tt.source)
+		switch {
+		case tt.reason == "" && len(mismatches) > 0:
+			t.Errorf("Expected no mismatch between %q and %q, got %+v", tt.claim, tt.source, mismatches)
+		case tt.reason != "" && len(mismatches) != 1:
+			t.Errorf("Expected 1 mismatch between %q and %q, got %+v", tt.claim, tt.source, mismatches)
+		case tt.reason != "" && mismatches[0].Reason != tt.reason:
+			t.Errorf("Expected mismatch between %q and %q to be a %s, got %q", tt.claim, tt.source, tt.reason, mismatches[0].Reason)
+		}
+	}
+}
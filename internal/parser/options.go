@@ -0,0 +1,262 @@
+package parser
+
+import (
+	"citation-scanner/internal/reliability"
+	"citation-scanner/internal/retraction"
+	"citation-scanner/pkg/archive"
+	"citation-scanner/pkg/identifier"
+	"citation-scanner/pkg/linkcheck"
+	"citation-scanner/pkg/openai"
+	"citation-scanner/pkg/webscraper"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Options holds the dependencies and settings used while scanning pages.
+type Options struct {
+	client openai.ChatClient
+	fetch  func(url string) (string, error)
+	prices map[string]ModelPrice
+	budget Budget
+	scope  Scope
+	verify bool
+
+	reputation  *reliability.Reputation
+	retractions *retraction.Database
+
+	stalenessThresholds map[string]time.Duration
+
+	linkChecker *linkcheck.Checker
+	archive     *archive.Resolver
+	identifiers *identifier.Resolver
+
+	quoteThreshold float64
+	dropUnmatched  bool
+
+	clusterThreshold    float64
+	embeddingClustering bool
+
+	maxContradictionPairs int
+
+	fetchConcurrency int
+	llmConcurrency   int
+}
+
+// WithClient is an option to use a custom LLM client, such as a cassette, instead of the OpenAI API.
+func WithClient(client openai.ChatClient) func(*Options) {
+	return func(o *Options) {
+		o.client = client
+	}
+}
+
+// WithFetcher is an option to use a custom function for fetching the raw HTML of a page.
+func WithFetcher(fetch func(url string) (string, error)) func(*Options) {
+	return func(o *Options) {
+		o.fetch = fetch
+	}
+}
+
+// WithPriceTable is an option to set the per-model prices used to estimate the cost of a scan.
+func WithPriceTable(prices map[string]ModelPrice) func(*Options) {
+	return func(o *Options) {
+		o.prices = prices
+	}
+}
+
+// WithBudget is an option to limit the pages, tokens, cost and time a recursive scan may use.
+func WithBudget(budget Budget) func(*Options) {
+	return func(o *Options) {
+		o.budget = budget
+	}
+}
+
+// WithScope is an option to restrict which sources a recursive scan follows.
+func WithScope(scope Scope) func(*Options) {
+	return func(o *Options) {
+		o.scope = scope
+	}
+}
+
+// WithReputation is an option to classify sources with a custom domain reputation list, such as one read by reliability.LoadReputation.
+func WithReputation(reputation *reliability.Reputation) func(*Options) {
+	return func(o *Options) {
+		o.reputation = reputation
+	}
+}
+
+// WithRetractions is an option to flag claims whose sources are in a retraction database, such as one read by retraction.Load.
+func WithRetractions(db *retraction.Database) func(*Options) {
+	return func(o *Options) {
+		o.retractions = db
+	}
+}
+
+// WithStalenessThresholds is an option to set how old a source of a time-sensitive claim may be, by claim type,
+// before it is flagged as stale. The "" entry applies to the claim types without their own threshold.
+func WithStalenessThresholds(thresholds map[string]time.Duration) func(*Options) {
+	return func(o *Options) {
+		o.stalenessThresholds = thresholds
+	}
+}
+
+// WithLinkChecker is an option to audit links with a custom checker, such as one with a shorter timeout.
+func WithLinkChecker(checker *linkcheck.Checker) func(*Options) {
+	return func(o *Options) {
+		o.linkChecker = checker
+	}
+}
+
+// WithArchiveFallback is an option to scan the closest archived copy of pages that cannot be fetched.
+// A nil resolver looks the copies up in the Wayback Machine.
+func WithArchiveFallback(resolver *archive.Resolver) func(*Options) {
+	return func(o *Options) {
+		if resolver == nil {
+			resolver = archive.NewResolver()
+		}
+		o.archive = resolver
+	}
+}
+
+// WithIdentifierResolver is an option to fetch sources given as bare DOIs, PubMed IDs, arXiv IDs or ISBNs through
+// custom resolver endpoints, such as an institutional proxy, instead of the default ones.
+func WithIdentifierResolver(resolver *identifier.Resolver) func(*Options) {
+	return func(o *Options) {
+		o.identifiers = resolver
+	}
+}
+
+// WithVerification is an option to check every claim of a recursive scan against each of its sources.
+func WithVerification() func(*Options) {
+	return func(o *Options) {
+		o.verify = true
+	}
+}
+
+// WithQuoteThreshold is an option to set the minimum quote score, between 0 and 1, a claim needs to count as found on its page.
+func WithQuoteThreshold(threshold float64) func(*Options) {
+	return func(o *Options) {
+		o.quoteThreshold = threshold
+	}
+}
+
+// WithDropUnmatchedQuotes is an option to drop claims that are not found on their page instead of flagging them.
+func WithDropUnmatchedQuotes() func(*Options) {
+	return func(o *Options) {
+		o.dropUnmatched = true
+	}
+}
+
+// WithClusterThreshold is an option to set the minimum word similarity, between 0 and 1, for claims to be grouped together.
+func WithClusterThreshold(threshold float64) func(*Options) {
+	return func(o *Options) {
+		o.clusterThreshold = threshold
+		o.embeddingClustering = false
+	}
+}
+
+// WithEmbeddingClustering is an option to group claims by the cosine similarity of their embeddings
+// from the LLM client instead of by their words. A threshold of zero selects the default.
+func WithEmbeddingClustering(threshold float64) func(*Options) {
+	return func(o *Options) {
+		o.clusterThreshold = threshold
+		o.embeddingClustering = true
+	}
+}
+
+// WithContradictionCheck is an option to have the LLM compare claims about the same subject from different
+// pages for incompatible statements. At most maxPairs pairs are compared; zero selects the default.
+func WithContradictionCheck(maxPairs int) func(*Options) {
+	return func(o *Options) {
+		if maxPairs <= 0 {
+			maxPairs = DefaultMaxContradictionPairs
+		}
+		o.maxContradictionPairs = maxPairs
+	}
+}
+
+// WithConcurrency is an option to set how many pages a recursive scan fetches, and how many
+// it sends to the LLM, at the same time. Values below one are ignored.
+func WithConcurrency(fetch, llm int) func(*Options) {
+	return func(o *Options) {
+		if fetch > 0 {
+			o.fetchConcurrency = fetch
+		}
+		if llm > 0 {
+			o.llmConcurrency = llm
+		}
+	}
+}
+
+// newOptions applies the given options and fills in the default client and fetcher where none were provided.
+func newOptions(opts ...func(*Options)) (*Options, error) {
+	o := applyOptions(opts...)
+	if o.client == nil {
+		client, err := defaultClient()
+		if err != nil {
+			return nil, err
+		}
+		o.client = client
+	}
+	return o, nil
+}
+
+// applyOptions applies the given options over the defaults, leaving the LLM client unset when none was provided,
+// for the modes that do not use it.
+func applyOptions(opts ...func(*Options)) *Options {
+	o := &Options{
+		fetch:            webscraper.FetchHTML,
+		prices:           DefaultPriceTable,
+		fetchConcurrency: 8, // Default concurrent page fetches
+		llmConcurrency:   4, // Default concurrent LLM requests
+		quoteThreshold:   DefaultQuoteThreshold,
+		reputation:       reliability.DefaultReputation(),
+		identifiers:      identifier.NewResolver(),
+
+		stalenessThresholds: DefaultStalenessThresholds,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.linkChecker == nil {
+		o.linkChecker = linkcheck.NewChecker(linkcheck.WithConcurrency(o.fetchConcurrency))
+	}
+	return o
+}
+
+// defaultClient builds the OpenAI client from the environment.
+// Setting OPENAI_CASSETTE to a file path records every response to it, or serves
+// responses from it without network access when OPENAI_CASSETTE_MODE is "replay".
+func defaultClient() (openai.ChatClient, error) {
+	// Load the .env file
+	envErr := godotenv.Load("configs/.env")
+
+	cassettePath := os.Getenv("OPENAI_CASSETTE")
+	if cassettePath != "" && openai.CassetteMode(os.Getenv("OPENAI_CASSETTE_MODE")) == openai.CassetteReplay {
+		return openai.NewReplayer(cassettePath)
+	}
+
+	if envErr != nil {
+		fmt.Println("Error loading .env file")
+		return nil, fmt.Errorf("Error loading .env file: %v", envErr)
+	}
+
+	// Get the OpenAI API key from environment variables
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("Environment variable OPENAI_API_KEY is required but not set")
+	}
+
+	// Create a new OpenAIClient instance with customized settings
+	client := openai.NewClient(apiKey,
+		openai.WithTemperature(0.1),
+		openai.WithSystemRole("You are an expert in extracting claims from articles."),
+	)
+
+	if cassettePath != "" {
+		return openai.NewRecorder(client, cassettePath)
+	}
+	return client, nil
+}
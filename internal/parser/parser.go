@@ -1,60 +1,136 @@
 package parser
 
 import (
-	"citation-scanner/internal/cache"
+	"citation-scanner/internal/reliability"
+	"citation-scanner/internal/retraction"
+	"citation-scanner/pkg/archive"
+	"citation-scanner/pkg/bibliography"
 	"citation-scanner/pkg/openai"
 	"citation-scanner/pkg/webscraper"
 	"encoding/json"
 	"fmt"
-	"os"
-	"sync"
-
-	"github.com/joho/godotenv"
+	"time"
 )
 
 // ParsedClaims represents the structure of the JSON object for claims and sources.
 type ParsedClaims struct {
-	Page      string  `json:"page"`
-	ParentURL string  `json:"parent_url,omitempty"`
-	Claims    []Claim `json:"claims"`
+	Page         string              `json:"page"`
+	ParentURL    string              `json:"parent_url,omitempty"`
+	Published    *time.Time          `json:"published,omitempty"`
+	Modified     *time.Time          `json:"modified,omitempty"`
+	Archived     *archive.Snapshot   `json:"archived,omitempty"`
+	Metadata     *PageMetadata       `json:"metadata,omitempty"`
+	Claims       []Claim             `json:"claims"`
+	Bibliography []bibliography.Item `json:"bibliography,omitempty"`
+	Dropped      int                 `json:"dropped_claims,omitempty"`
+	Coverage     *CitationCoverage   `json:"coverage,omitempty"`
+	Usage        *Usage              `json:"usage,omitempty"`
+}
+
+// PageMetadata is what the meta tags of a page tell about it as a source. It is kept with the claims of the
+// page so that pages served from the cache are assessed the same as freshly fetched ones.
+type PageMetadata struct {
+	Signals     *reliability.Signals   `json:"signals,omitempty"`
+	Identifiers retraction.Identifiers `json:"identifiers"`
 }
 
 // Claim represents a single claim and its source.
 type Claim struct {
-	Claim  string   `json:"claim"`
-	Source []string `json:"sources"`
+	Claim       string         `json:"claim"`
+	Type        string         `json:"type,omitempty"`
+	Speaker     string         `json:"speaker,omitempty"`
+	Source      []string       `json:"sources"`
+	Details     []SourceRecord `json:"source_details,omitempty"`
+	QuoteScore  float64        `json:"quote_score"`
+	Reliability float64        `json:"reliability"`
+	Flags       []string       `json:"flags,omitempty"`
+	ClusterID   int            `json:"cluster_id,omitempty"`
 }
 
 // AggregatedClaims represents the structure for the aggregated claims from multiple sources.
 type AggregatedClaims struct {
-	RootPage  string         `json:"root_page"`
-	AllClaims []ParsedClaims `json:"all_claims"`
-	Errors    []string       `json:"errors"`
+	RootPage       string          `json:"root_page"`
+	AllClaims      []ParsedClaims  `json:"all_claims"`
+	Errors         []string        `json:"errors"`
+	Skipped        []SkippedURL    `json:"skipped"`
+	Cycles         []Cycle         `json:"cycles"`
+	Laundering     []Laundering    `json:"laundering"`
+	Clusters       []ClaimCluster  `json:"clusters"`
+	Contradictions []Contradiction `json:"contradictions"`
+	Usage          Usage           `json:"usage"`
+
+	// failed holds the URLs whose fetching or parsing failed during the scan.
+	failed map[string]bool
 }
 
 // ParsePageClaims takes a URL, scrapes the content, and uses OpenAI to extract claims and their sources.
-func ParsePageClaims(url string) (*ParsedClaims, error) {
+func ParsePageClaims(url string, opts ...func(*Options)) (*ParsedClaims, error) {
+	o, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return parsePage(url, o)
+}
 
-	// Load the .env file
-	if err := godotenv.Load("configs/.env"); err != nil {
-		fmt.Println("Error loading .env file")
-		return nil, fmt.Errorf("Error loading .env file: %v", err)
+// parsePage scrapes a single page and extracts its claims using the configured client and fetcher.
+func parsePage(url string, o *Options) (*ParsedClaims, error) {
+	// Step 1: Scrape the content of the page, or of its archived copy
+	page, fetchedURL, snapshot, err := fetchPage(url, archiveHint{}, o)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scrape the page: %v", err)
+	}
+	parsedClaims, err := extractClaims(url, fetchedURL, page, o)
+	if err != nil {
+		return nil, err
 	}
+	parsedClaims.Archived = snapshot
+	return parsedClaims, nil
+}
 
-	// Get the OpenAI API key from environment variables
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("Environment variable OPENAI_API_KEY is required but not set")
+// fetchPage fetches the HTML of a page. When the page was cited as dead with an archived copy, that copy is
+// tried first. When the page cannot be fetched and an archive is configured, the archived copy closest to when
+// the citing page accessed it, or else the most recent one, is fetched instead, unless the archive captured an
+// error or a redirect. The page is returned with the URL it was fetched from, which is the landing page of a
+// source given as a bare identifier, and with the snapshot of an archived copy, which is otherwise nil.
+func fetchPage(url string, hint archiveHint, o *Options) (string, string, *archive.Snapshot, error) {
+	// Sources given as bare identifiers are fetched from their landing page
+	url = o.identifiers.FetchURL(url)
+
+	if hint.url != "" {
+		snapshot := archive.SnapshotFromURL(hint.url)
+		if snapshot.OriginalURL == "" {
+			snapshot.OriginalURL = url
+		}
+		if page, err := o.fetch(snapshot.RawURL()); err == nil {
+			return page, url, snapshot, nil
+		}
 	}
 
-	// Create a new OpenAIClient instance with customized settings
-	openAIClient := openai.NewClient(apiKey,
-		openai.WithTemperature(0.1),
-		openai.WithSystemRole("You are an expert in extracting claims from articles."),
-	)
+	page, err := o.fetch(url)
+	if err == nil || o.archive == nil {
+		return page, url, nil, err
+	}
 
-	// Step 1: Scrape the content of the page using the webscraper package
-	scrapedContent, err := webscraper.ScrapeBody(url)
+	snapshot, archiveErr := o.archive.Closest(url, hint.accessed)
+	switch {
+	case archiveErr != nil:
+		return "", "", nil, fmt.Errorf("%v; archive lookup failed: %v", err, archiveErr)
+	case snapshot == nil:
+		return "", "", nil, fmt.Errorf("%v; no archived copy found", err)
+	case snapshot.Status != 0 && (snapshot.Status < 200 || snapshot.Status >= 300):
+		return "", "", nil, fmt.Errorf("%v; the closest archived copy has HTTP status %d", err, snapshot.Status)
+	}
+	page, archiveErr = o.fetch(snapshot.RawURL())
+	if archiveErr != nil {
+		return "", "", nil, fmt.Errorf("%v; failed to fetch the archived copy: %v", err, archiveErr)
+	}
+	return page, url, snapshot, nil
+}
+
+// extractClaims uses the configured client to extract the claims and sources from the HTML of a fetched page.
+// The page keeps the URL it was cited by, while its links are resolved against the URL it was fetched from.
+func extractClaims(url, fetchedURL, page string, o *Options) (*ParsedClaims, error) {
+	scrapedContent, err := webscraper.BodyText(page)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scrape the page: %v", err)
 	}
@@ -68,25 +144,26 @@ func ParsePageClaims(url string) (*ParsedClaims, error) {
 		There can be more than one source to a claim, so return them in an array of strings.
 		Make sure that the claims extracted are direct quotes from the scraped page text; prefix and/or postfix with "..." if a quoted claim is a section of a sentence.
 		Provide the actual citation links to the associated sources, not the reference markers.
+		Classify each claim with a "type" that is one of "statistic", "quotation", "causal", "definition", "opinion" or "event".
+		For a quotation, give the person or organization it is attributed to as its "speaker".
 		DO NOT wrap response with Markdown code-block formatting. DO NOT omit any claims or sources from the content in your response.
 		ALL CLAIMS AND SOURCES MUST BE RETURNED, REGARDLESS OF PROCESSING TIME OR LENGTH OF RESPONSE.
 		Respond only with a JSON object formatted as follows:
 		{
 			"claims": [
-				{"claim": "... Example claim 1[34][35].", "sources": ["https://www.example-source-1.com/article1", "https://www.example-source-1.org/"]},
-				{"claim": "... Example claim 2[65] ...", "sources": ["https://www.example-source-2.com/"]}
+				{"claim": "... Example claim 1[34][35].", "type": "statistic", "sources": ["https://www.example-source-1.com/article1", "https://www.example-source-1.org/"]},
+				{"claim": "... Example claim 2[65] ...", "type": "quotation", "speaker": "Example Person", "sources": ["https://www.example-source-2.com/"]}
 			]
 		}
 		Content: "%s"
 	`, scrapedContent)
 
 	// Step 3: Use OpenAIClient to get claims from the scraped content
-	response, err := openAIClient.SendChatRequest(prompt)
+	completion, err := o.client.Complete(prompt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract claims: %v", err)
 	}
-
-	fmt.Println(response)
+	response := completion.Content
 
 	// Step 4: Parse the JSON response
 	var parsedClaims ParsedClaims
@@ -95,110 +172,154 @@ func ParsePageClaims(url string) (*ParsedClaims, error) {
 		return nil, fmt.Errorf("failed to parse response as JSON: %v", err)
 	}
 
-	// Ensure that each claim's Source is an empty array if it's nil
+	// Ensure that each claim's Source is an empty array if it's nil, and that its type is a known one
 	for i := range parsedClaims.Claims {
 		if parsedClaims.Claims[i].Source == nil {
 			parsedClaims.Claims[i].Source = []string{}
 		}
+		parsedClaims.Claims[i].normalizeType()
+	}
+
+	// Step 5: Check that the claims are quoted from the page rather than paraphrased or invented
+	parsedClaims.Claims, parsedClaims.Dropped = checkQuotes(parsedClaims.Claims, scrapedContent, o.quoteThreshold, o.dropUnmatched)
+
+	// Step 6: Attach the bibliographic records, archive links and access dates of the page's reference list to the sources,
+	// and recognize the DOIs, PubMed IDs, arXiv IDs and ISBNs of the sources
+	if references, err := webscraper.References(page, fetchedURL); err == nil {
+		parsedClaims.Bibliography = attachReferences(parsedClaims.Claims, references)
 	}
+	identifySources(parsedClaims.Claims)
 
-	// Step 5: Set the page URL in the parsed claims
+	// Step 7: Report claims without a proper source
+	parsedClaims.Coverage = analyzeCoverage(parsedClaims.Claims, scrapedContent)
+
+	// Step 8: Rate the sources by their domain, and look their DOIs up in the retraction database
+	assessSources(parsedClaims.Claims, o.reputation, nil)
+	if o.retractions != nil {
+		checkRetractions(parsedClaims.Claims, o.retractions, nil)
+	}
+
+	// Step 9: Set the page URL, its publication dates, its metadata and the token usage in the parsed claims
 	parsedClaims.Page = url
+	dates := webscraper.ExtractPublicationDates(page)
+	parsedClaims.Published, parsedClaims.Modified = timePtr(dates.Published), timePtr(dates.Modified)
+	parsedClaims.Metadata = &PageMetadata{Signals: reliability.PageSignals(page), Identifiers: retraction.PageIdentifiers(page)}
+	parsedClaims.Usage = &Usage{}
+	parsedClaims.Usage.addCompletion(completion, o.prices)
 
 	return &parsedClaims, nil
 }
 
-// ParseAndAggregateClaims recursively parses a page and its sources, aggregating all claims.
-func ParseAndAggregateClaims(rootURL string, maxDepth int) (*AggregatedClaims, error) {
+// ParseAndAggregateClaims parses a page and then its sources breadth-first up to maxDepth, aggregating all claims.
+// Pages of the same depth are fetched and parsed by bounded worker pools, and AllClaims is ordered
+// by depth and then by the order in which each page was first cited.
+func ParseAndAggregateClaims(rootURL string, maxDepth int, opts ...func(*Options)) (*AggregatedClaims, error) {
+	o, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
 	aggregatedClaims := &AggregatedClaims{
 		RootPage:  rootURL,
 		AllClaims: []ParsedClaims{},
 		Errors:    []string{},
+		Skipped:   []SkippedURL{},
+		failed:    make(map[string]bool),
 	}
-	visited := make(map[string]bool)
-	var mu sync.Mutex
-	var wg sync.WaitGroup
+	scan := newScanState(o)
+	visited := map[string]bool{rootURL: true}
+	frontier := []scanTask{{url: rootURL}}
 
-	var parseRecursive func(url, parentURL string, depth int)
-	parseRecursive = func(url, parentURL string, depth int) {
-		if depth > maxDepth {
-			return
-		}
+	for depth := 0; depth <= maxDepth && len(frontier) > 0; depth++ {
+		results := scan.runLevel(frontier)
 
-		mu.Lock()
-		if visited[url] {
-			mu.Unlock()
-			fmt.Printf("Circular dependency detected at URL: %s\n", url)
-			return
-		}
-		visited[url] = true
-		mu.Unlock()
-
-		wg.Add(1)
-		go func(url, parentURL string, depth int) {
-			defer wg.Done()
-
-			// Check the cache
-			cachedResponse, found, err := cache.GetCachedResponse(url)
-			var claims *ParsedClaims
-			if err != nil {
-				mu.Lock()
-				aggregatedClaims.Errors = append(aggregatedClaims.Errors, fmt.Sprintf("Error accessing cache for %s: %v", url, err))
-				mu.Unlock()
-				return
+		var next []scanTask
+		for i, result := range results {
+			task := frontier[i]
+			switch {
+			case result.skipped != "":
+				aggregatedClaims.Skipped = append(aggregatedClaims.Skipped, SkippedURL{URL: task.url, ParentURL: task.parentURL, Reason: result.skipped})
+				continue
+			case result.err != "":
+				aggregatedClaims.Errors = append(aggregatedClaims.Errors, result.err)
+				aggregatedClaims.failed[task.url] = true
+				continue
 			}
 
-			if found {
-				err = json.Unmarshal([]byte(cachedResponse), &claims)
-				if err != nil {
-					mu.Lock()
-					aggregatedClaims.Errors = append(aggregatedClaims.Errors, fmt.Sprintf("Error unmarshaling cache for %s: %v", url, err))
-					mu.Unlock()
-					return
-				}
-			} else {
-				claims, err = ParsePageClaims(url)
-				if err != nil {
-					mu.Lock()
-					aggregatedClaims.Errors = append(aggregatedClaims.Errors, fmt.Sprintf("Error parsing %s: %v", url, err))
-					mu.Unlock()
-					return
-				}
-
-				claimsJSON, err := json.Marshal(claims)
-				if err != nil {
-					mu.Lock()
-					aggregatedClaims.Errors = append(aggregatedClaims.Errors, fmt.Sprintf("Error marshaling claims for %s: %v", url, err))
-					mu.Unlock()
-					return
-				}
-				err = cache.CacheResponse(url, string(claimsJSON))
-				if err != nil {
-					mu.Lock()
-					aggregatedClaims.Errors = append(aggregatedClaims.Errors, fmt.Sprintf("Error caching response for %s: %v", url, err))
-					mu.Unlock()
-					return
-				}
-			}
-
-			// Add parentURL to claims
-			claims.ParentURL = parentURL
-
-			// Aggregate the claims
-			mu.Lock()
+			// Add parentURL to claims and aggregate them
+			claims := result.claims
+			claims.ParentURL = task.parentURL
 			aggregatedClaims.AllClaims = append(aggregatedClaims.AllClaims, *claims)
-			mu.Unlock()
+			if depth == maxDepth {
+				continue
+			}
 
-			// Recursively parse sources
+			// Queue the sources for the next depth
 			for _, claim := range claims.Claims {
 				for _, source := range claim.Source {
-					parseRecursive(source, url, depth+1)
+					if visited[source] {
+						continue
+					}
+					visited[source] = true
+					if rule := o.scope.check(o.identifiers.FetchURL(source), rootURL); rule != "" {
+						aggregatedClaims.Skipped = append(aggregatedClaims.Skipped, SkippedURL{URL: source, ParentURL: task.url, Reason: rule})
+						continue
+					}
+					next = append(next, scanTask{url: source, parentURL: task.url, archive: claim.sourceArchive(source)})
 				}
 			}
-		}(url, parentURL, depth)
+		}
+		frontier = next
+	}
+
+	// Check whether each source backs the claims that cite it
+	if o.verify {
+		scan.verifyPages(aggregatedClaims.AllClaims, rootURL, aggregatedClaims.Skipped)
+	}
+
+	// Rate the sources again now that the pages fetched during the scan can add their metadata
+	for i := range aggregatedClaims.AllClaims {
+		assessSources(aggregatedClaims.AllClaims[i].Claims, o.reputation, scan.fetched)
+		if o.retractions != nil {
+			checkRetractions(aggregatedClaims.AllClaims[i].Claims, o.retractions, scan.fetched)
+		}
+	}
+
+	// Mark the sources that were scanned from an archived copy
+	markArchived(aggregatedClaims.AllClaims, scan.fetched)
+
+	// Flag sources dated after the pages citing them, and old sources of time-sensitive claims
+	checkDates(aggregatedClaims.AllClaims, scan.fetched, o.stalenessThresholds, time.Now())
+
+	// Look for pages that cite each other in a loop
+	aggregatedClaims.Cycles = buildCitationGraph(aggregatedClaims.AllClaims).cycles()
+
+	aggregatedClaims.Usage = scan.usage
+
+	// Group the equivalent claims made across pages
+	var embedder openai.Embedder
+	threshold := o.clusterThreshold
+	if o.embeddingClustering {
+		if e, ok := o.client.(openai.Embedder); ok {
+			embedder = e
+		} else {
+			// Word similarity uses a different scale, so fall back to its default threshold as well
+			threshold = 0
+			aggregatedClaims.Errors = append(aggregatedClaims.Errors, "Error clustering claims: the client does not support embeddings, falling back to word similarity")
+		}
+	}
+	if err := ClusterClaims(aggregatedClaims, threshold, embedder, o.prices); err != nil {
+		aggregatedClaims.Errors = append(aggregatedClaims.Errors, fmt.Sprintf("Error clustering claims: %v", err))
+	}
+
+	// Look for claims whose sources all trace back to the same origin, matching facts across pages by their clusters
+	aggregatedClaims.Laundering = detectLaundering(aggregatedClaims)
+
+	// Compare claims about the same subject for incompatible statements
+	aggregatedClaims.Contradictions = []Contradiction{}
+	if o.maxContradictionPairs > 0 {
+		aggregatedClaims.Contradictions = scan.findContradictions(aggregatedClaims, o.maxContradictionPairs)
 	}
 
-	parseRecursive(rootURL, "", 0)
-	wg.Wait()
 	return aggregatedClaims, nil
 }
@@ -2,18 +2,75 @@ package parser
 
 import (
 	"citation-scanner/internal/cache"
+	"citation-scanner/pkg/openai"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
-// TestParsePageClaims tests the ParsePageClaims function by scraping a real page and using the OpenAI client.
-func TestParsePageClaims(t *testing.T) {
-	// Define the page URL to scrape and parse
-	url := "https://en.wikipedia.org/wiki/Go_(programming_language)"
+// rootFixtureURL is the page the offline tests start scanning from.
+const rootFixtureURL = "https://example.org/wiki/Trust"
+
+// fixturePages maps the URLs used in the offline tests to the HTML files served for them.
+var fixturePages = map[string]string{
+	rootFixtureURL:                        "testdata/pages/trust.html",
+	"https://example.org/papers/fukuyama": "testdata/pages/fukuyama.html",
+	"https://example.net/survey":          "testdata/pages/survey.html",
+}
+
+// fixtureSnapshots maps the archived copies of fixture pages to the pages they are a copy of.
+// The root page marks the survey as dead, so scans read its archived copy.
+var fixtureSnapshots = map[string]string{
+	"https://web.archive.org/web/20210310000000id_/https://example.net/survey": "https://example.net/survey",
+}
+
+// fixtureFetcher serves the fixture pages from disk instead of the network.
+func fixtureFetcher(url string) (string, error) {
+	if original, ok := fixtureSnapshots[url]; ok {
+		url = original
+	}
+	path, ok := fixturePages[url]
+	if !ok {
+		return "", fmt.Errorf("unexpected HTTP status: 404 Not Found")
+	}
+	page, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(page), nil
+}
+
+// testOptions returns the options that make a scan run against the fixtures with the given client.
+func testOptions(client openai.ChatClient) []func(*Options) {
+	return []func(*Options){WithClient(client), WithFetcher(fixtureFetcher)}
+}
+
+// replayOptions returns the offline options backed by the recorded cassette.
+func replayOptions(t *testing.T) []func(*Options) {
+	t.Helper()
+	replayer, err := openai.NewReplayer("testdata/cassette.json")
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	return testOptions(replayer)
+}
+
+// initTestCache opens a fresh cache database in a temporary directory.
+func initTestCache(t *testing.T) error {
+	t.Helper()
+	if err := cache.InitializeCacheAt(filepath.Join(t.TempDir(), "cache.db")); err != nil {
+		return err
+	}
+	t.Cleanup(cache.CloseCache)
+	return nil
+}
 
+// TestParsePageClaims tests the ParsePageClaims function against a fixture page and the recorded cassette.
+func TestParsePageClaims(t *testing.T) {
 	// Parse the claims using the parser package
-	parsedClaims, err := ParsePageClaims(url)
+	parsedClaims, err := ParsePageClaims(rootFixtureURL, replayOptions(t)...)
 	if err != nil {
 		t.Fatalf("Error parsing claims: %v", err)
 	}
@@ -24,25 +81,39 @@ func TestParsePageClaims(t *testing.T) {
 		t.Fatalf("Error marshalling claims to JSON: %v", err)
 	}
 	fmt.Println(string(claimsJSON))
+
+	if parsedClaims.Page != rootFixtureURL {
+		t.Errorf("Expected page %s, got %s", rootFixtureURL, parsedClaims.Page)
+	}
+	if len(parsedClaims.Claims) != 5 {
+		t.Fatalf("Expected 5 claims, got %d", len(parsedClaims.Claims))
+	}
+	for _, claim := range parsedClaims.Claims {
+		if claim.Source == nil {
+			t.Errorf("Expected an empty source slice for claim %q, got nil", claim.Claim)
+		}
+	}
+}
+
+// TestParsePageClaimsScrapeError verifies that fetch failures are returned as errors.
+func TestParsePageClaimsScrapeError(t *testing.T) {
+	if _, err := ParsePageClaims("https://example.org/missing", replayOptions(t)...); err == nil {
+		t.Error("Expected an error for a page that cannot be fetched, got nil")
+	}
 }
 
 // TestParseAndAggregateClaims tests the ParseAndAggregateClaims function by parsing a root page and its sources.
 func TestParseAndAggregateClaims(t *testing.T) {
 	// Initialize cache for testing
-	err := cache.InitializeCache()
-	if err != nil {
+	if err := initTestCache(t); err != nil {
 		t.Fatalf("Failed to initialize cache: %v", err)
 	}
-	defer cache.CloseCache()
-
-	// Define the root page URL for the test
-	rootURL := "https://en.wikipedia.org/wiki/High-trust_and_low-trust_societies"
 
 	// Define the maximum depth for recursion
 	maxDepth := 1
 
 	// Call the ParseAndAggregateClaims function
-	aggregatedClaims, err := ParseAndAggregateClaims(rootURL, maxDepth)
+	aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, maxDepth, replayOptions(t)...)
 	if err != nil {
 		t.Fatalf("Error parsing and aggregating claims: %v", err)
 	}
@@ -55,12 +126,12 @@ func TestParseAndAggregateClaims(t *testing.T) {
 	fmt.Println(string(claimsJSON))
 
 	// Validate the aggregated claims
-	if len(aggregatedClaims.AllClaims) == 0 {
-		t.Error("Expected to find claims, but none were found")
+	if len(aggregatedClaims.AllClaims) != len(fixturePages) {
+		t.Errorf("Expected claims from %d pages, got %d", len(fixturePages), len(aggregatedClaims.AllClaims))
 	}
 
 	// Check for errors in the aggregated results
 	if len(aggregatedClaims.Errors) > 0 {
-		t.Logf("Encountered errors during parsing: %v", aggregatedClaims.Errors)
+		t.Errorf("Encountered errors during parsing: %v", aggregatedClaims.Errors)
 	}
 }
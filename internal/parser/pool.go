@@ -0,0 +1,213 @@
+package parser
+
+import (
+	"citation-scanner/internal/cache"
+	"citation-scanner/internal/reliability"
+	"citation-scanner/internal/retraction"
+	"citation-scanner/pkg/archive"
+	"citation-scanner/pkg/webscraper"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// scanTask is a page waiting in the frontier of a scan, along with the page that cited it and what that
+// page tells about its archived copies.
+type scanTask struct {
+	url       string
+	parentURL string
+	archive   archiveHint
+}
+
+// scanResult is the outcome of a single scanTask. Exactly one of claims, err and skipped is set.
+type scanResult struct {
+	claims  *ParsedClaims
+	err     string
+	skipped string
+}
+
+// extractJob is a fetched page waiting for the LLM stage, with the URL it was fetched from and its snapshot
+// when it was fetched from an archive.
+type extractJob struct {
+	index      int
+	page       string
+	fetchedURL string
+	snapshot   *archive.Snapshot
+}
+
+// scanState holds the progress of a scan that is shared between its workers.
+type scanState struct {
+	o       *Options
+	started time.Time
+	mu      sync.Mutex
+	pages   int
+	usage   Usage
+	fetched map[string]*fetchedPage
+	// html keeps the pages fetched during the scan when they are verified later, so that they are not fetched again
+	html map[string]string
+}
+
+// fetchedPage is what a scan keeps from the HTML of a page it fetched, for assessing the page as a source later.
+type fetchedPage struct {
+	signals     *reliability.Signals
+	identifiers retraction.Identifiers
+	dates       webscraper.PublicationDates
+	archived    *archive.Snapshot
+}
+
+// newScanState starts tracking a new scan.
+func newScanState(o *Options) *scanState {
+	return &scanState{o: o, started: time.Now(), fetched: make(map[string]*fetchedPage), html: make(map[string]string)}
+}
+
+// recordClaims keeps the metadata of a parsed page, fetched or served from the cache, for assessing it as a source later.
+func (s *scanState) recordClaims(url string, claims *ParsedClaims) {
+	fetched := &fetchedPage{archived: claims.Archived}
+	if claims.Published != nil {
+		fetched.dates.Published = *claims.Published
+	}
+	if claims.Modified != nil {
+		fetched.dates.Modified = *claims.Modified
+	}
+	if claims.Metadata != nil {
+		fetched.signals = claims.Metadata.Signals
+		fetched.identifiers = claims.Metadata.Identifiers
+	}
+	s.mu.Lock()
+	s.fetched[url] = fetched
+	s.mu.Unlock()
+}
+
+// recordPage keeps the metadata of a page fetched without being parsed for assessing it as a source later.
+func (s *scanState) recordPage(url, page string, snapshot *archive.Snapshot) {
+	fetched := &fetchedPage{
+		signals:     reliability.PageSignals(page),
+		identifiers: retraction.PageIdentifiers(page),
+		dates:       webscraper.ExtractPublicationDates(page),
+		archived:    snapshot,
+	}
+	s.mu.Lock()
+	s.fetched[url] = fetched
+	s.mu.Unlock()
+}
+
+// runLevel parses every task of one depth of the frontier. Pages are fetched by a pool of
+// fetchConcurrency workers and handed to a pool of llmConcurrency workers for extraction.
+// The results are returned in the same order as the tasks.
+func (s *scanState) runLevel(tasks []scanTask) []scanResult {
+	results := make([]scanResult, len(tasks))
+	fetchQueue := make(chan int)
+	extractQueue := make(chan extractJob)
+	var fetchWG, extractWG sync.WaitGroup
+
+	for w := 0; w < s.o.fetchConcurrency; w++ {
+		fetchWG.Add(1)
+		go func() {
+			defer fetchWG.Done()
+			for i := range fetchQueue {
+				if job, ok := s.fetch(tasks[i], &results[i]); ok {
+					job.index = i
+					extractQueue <- job
+				}
+			}
+		}()
+	}
+	for w := 0; w < s.o.llmConcurrency; w++ {
+		extractWG.Add(1)
+		go func() {
+			defer extractWG.Done()
+			for job := range extractQueue {
+				s.extract(tasks[job.index], job, &results[job.index])
+			}
+		}()
+	}
+
+	// Feed the tasks in order so that the page budget is spent on the earliest cited pages first
+	for i := range tasks {
+		if reason := s.reserve(); reason != "" {
+			results[i].skipped = reason
+			continue
+		}
+		fetchQueue <- i
+	}
+	close(fetchQueue)
+	fetchWG.Wait()
+	close(extractQueue)
+	extractWG.Wait()
+
+	return results
+}
+
+// reserve claims a page from the budget, returning the exhausted limit if no more pages may be started.
+func (s *scanState) reserve() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if reason := s.o.budget.exhausted(s.pages, s.usage, time.Since(s.started)); reason != "" {
+		return reason
+	}
+	s.pages++
+	return ""
+}
+
+// fetch serves a task from the cache or fetches its page. It returns the fetched page for the LLM stage,
+// and true when the page still needs to go through it.
+func (s *scanState) fetch(task scanTask, result *scanResult) (extractJob, bool) {
+	// Check the cache
+	cachedResponse, found, err := cache.GetCachedResponse(task.url)
+	if err != nil {
+		result.err = fmt.Sprintf("Error accessing cache for %s: %v", task.url, err)
+		return extractJob{}, false
+	}
+	if found {
+		var claims *ParsedClaims
+		if err := json.Unmarshal([]byte(cachedResponse), &claims); err != nil {
+			result.err = fmt.Sprintf("Error unmarshaling cache for %s: %v", task.url, err)
+			return extractJob{}, false
+		}
+		s.recordClaims(task.url, claims)
+		result.claims = claims
+		return extractJob{}, false
+	}
+
+	page, fetchedURL, snapshot, err := fetchPage(task.url, task.archive, s.o)
+	if err != nil {
+		result.err = fmt.Sprintf("Error parsing %s: failed to scrape the page: %v", task.url, err)
+		return extractJob{}, false
+	}
+	if s.o.verify {
+		s.mu.Lock()
+		s.html[task.url] = page
+		s.mu.Unlock()
+	}
+	return extractJob{page: page, fetchedURL: fetchedURL, snapshot: snapshot}, true
+}
+
+// extract runs the LLM stage for a fetched page and caches its claims.
+func (s *scanState) extract(task scanTask, job extractJob, result *scanResult) {
+	claims, err := extractClaims(task.url, job.fetchedURL, job.page, s.o)
+	if err != nil {
+		// The page can still be assessed as a source by its HTML
+		s.recordPage(task.url, job.page, job.snapshot)
+		result.err = fmt.Sprintf("Error parsing %s: %v", task.url, err)
+		return
+	}
+	claims.Archived = job.snapshot
+	s.recordClaims(task.url, claims)
+
+	// Pages served from the cache cost nothing in this scan, so only fresh extractions are counted
+	s.mu.Lock()
+	s.usage.add(claims.Usage)
+	s.mu.Unlock()
+
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		result.err = fmt.Sprintf("Error marshaling claims for %s: %v", task.url, err)
+		return
+	}
+	if err := cache.CacheResponse(task.url, string(claimsJSON)); err != nil {
+		result.err = fmt.Sprintf("Error caching response for %s: %v", task.url, err)
+		return
+	}
+	result.claims = claims
+}
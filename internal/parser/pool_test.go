@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestParseAndAggregateClaimsOrdering verifies that pages are aggregated breadth-first in the order they were cited.
+func TestParseAndAggregateClaimsOrdering(t *testing.T) {
+	want := []string{rootFixtureURL, "https://example.org/papers/fukuyama", "https://example.net/survey"}
+
+	for run := 0; run < 5; run++ {
+		if err := initTestCache(t); err != nil {
+			t.Fatalf("Failed to initialize cache: %v", err)
+		}
+		aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, 1, replayOptions(t)...)
+		if err != nil {
+			t.Fatalf("Error parsing and aggregating claims: %v", err)
+		}
+		if len(aggregatedClaims.AllClaims) != len(want) {
+			t.Fatalf("Expected claims from %d pages, got %d", len(want), len(aggregatedClaims.AllClaims))
+		}
+		for i, page := range aggregatedClaims.AllClaims {
+			if page.Page != want[i] {
+				t.Errorf("Run %d: expected page %d to be %s, got %s", run, i, want[i], page.Page)
+			}
+		}
+	}
+}
+
+// TestParseAndAggregateClaimsConcurrency verifies that no more pages are fetched at once than configured.
+func TestParseAndAggregateClaimsConcurrency(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	countingFetcher := func(url string) (string, error) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return fixtureFetcher(url)
+	}
+
+	opts := append(replayOptions(t), WithFetcher(countingFetcher), WithConcurrency(1, 1))
+	aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, 1, opts...)
+	if err != nil {
+		t.Fatalf("Error parsing and aggregating claims: %v", err)
+	}
+	if len(aggregatedClaims.AllClaims) != 3 {
+		t.Errorf("Expected claims from 3 pages, got %d", len(aggregatedClaims.AllClaims))
+	}
+	if maxInFlight != 1 {
+		t.Errorf("Expected at most 1 concurrent fetch, got %d", maxInFlight)
+	}
+}
@@ -0,0 +1,246 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reasons a provenance chain stops before reaching a source that states the fact itself.
+const (
+	BreakDeadLink    = "dead_link"
+	BreakUnsupported = "unsupported"
+	BreakUnsourced   = "unsourced"
+	BreakNotScanned  = "not_scanned"
+	BreakCircular    = "circular"
+)
+
+// provenanceThreshold is the minimum similarity for a claim on a source to count as the same fact.
+const provenanceThreshold = 0.5
+
+// Provenance traces a claim through successive sources to the earliest page it could be followed to.
+type Provenance struct {
+	Page   string          `json:"page"`
+	Claim  string          `json:"claim"`
+	Origin string          `json:"origin"`
+	Hops   int             `json:"hops"`
+	Chain  []ProvenanceHop `json:"chain"`
+	// Break is why the chain stopped, empty when it ended at a source that states the fact itself.
+	Break string `json:"break,omitempty"`
+	// BrokenAt is the source that could not be followed when Break is set.
+	BrokenAt string `json:"broken_at,omitempty"`
+}
+
+// ProvenanceHop is one page of a provenance chain and the claim on it that states the fact.
+type ProvenanceHop struct {
+	Page       string  `json:"page"`
+	Claim      string  `json:"claim,omitempty"`
+	Similarity float64 `json:"similarity"`
+}
+
+// TraceProvenance scans rootURL and its sources, and traces every claim of the root page to its origin.
+func TraceProvenance(rootURL string, maxDepth int, opts ...func(*Options)) ([]Provenance, error) {
+	aggregatedClaims, err := ParseAndAggregateClaims(rootURL, maxDepth, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	provenance := []Provenance{}
+	for _, page := range aggregatedClaims.AllClaims {
+		if page.Page != rootURL {
+			continue
+		}
+		for _, claim := range page.Claims {
+			provenance = append(provenance, TraceClaim(aggregatedClaims, page.Page, claim))
+		}
+	}
+	return provenance, nil
+}
+
+// TraceClaim follows a claim made on a page of an aggregation through the sources that state the
+// same fact. When a claim has several sources, the longest chain is reported. A claim on a source states
+// the same fact when it is in the same cluster, compared by embeddings with WithEmbeddingClustering, or
+// otherwise when enough of its words match.
+func TraceClaim(aggregatedClaims *AggregatedClaims, page string, claim Claim) Provenance {
+	t := newProvenanceTracer(aggregatedClaims)
+	chain := t.trace(page, claim, map[string]bool{page: true})
+
+	p := Provenance{
+		Page:     page,
+		Claim:    claim.Claim,
+		Chain:    append([]ProvenanceHop{{Page: page, Claim: claim.Claim, Similarity: 1}}, chain.hops...),
+		Break:    chain.reason,
+		BrokenAt: chain.brokenAt,
+	}
+	p.Hops = len(p.Chain) - 1
+	p.Origin = p.Chain[len(p.Chain)-1].Page
+	return p
+}
+
+// provenanceTracer indexes an aggregation by page for tracing, and remembers the chains it has followed.
+type provenanceTracer struct {
+	pages  map[string]*ParsedClaims
+	failed map[string]bool
+	traced map[string]tracedChain
+}
+
+// tracedChain is the chain followed from a claim, and why and where it stopped. It also records the
+// sources whose pages were looked at, and whether any of them was left because it was already on the chain.
+type tracedChain struct {
+	hops     []ProvenanceHop
+	reason   string
+	brokenAt string
+	explored map[string]bool
+	circular bool
+}
+
+func newProvenanceTracer(aggregatedClaims *AggregatedClaims) *provenanceTracer {
+	t := &provenanceTracer{
+		pages:  make(map[string]*ParsedClaims),
+		failed: aggregatedClaims.failed,
+		traced: make(map[string]tracedChain),
+	}
+	for i := range aggregatedClaims.AllClaims {
+		t.pages[aggregatedClaims.AllClaims[i].Page] = &aggregatedClaims.AllClaims[i]
+	}
+	return t
+}
+
+// traceKey identifies a claim on a page by everything that following it depends on: its text, cluster,
+// sources, and the verdicts and quotes of their verification.
+func traceKey(page string, claim Claim) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\x00%d\x00%s", page, claim.ClusterID, claim.Claim)
+	for _, source := range claim.Source {
+		fmt.Fprintf(&sb, "\x00%s\x00%s\x00%s", source, verificationOf(claim, source), quoteOf(claim, source))
+	}
+	return sb.String()
+}
+
+// trace returns the chain that follows a claim on page, with why and where the longest chain stopped.
+// A chain is remembered unless it ran into a page already on the chain, and is only reused when none of the
+// pages it looked at are on the current chain, so that it is the same as if it were followed again.
+func (t *provenanceTracer) trace(page string, claim Claim, visited map[string]bool) tracedChain {
+	if len(claim.Source) == 0 {
+		return tracedChain{reason: BreakUnsourced}
+	}
+
+	key := traceKey(page, claim)
+	if traced, ok := t.traced[key]; ok && !overlaps(traced.explored, visited) {
+		return traced
+	}
+
+	var best tracedChain
+	explored := make(map[string]bool)
+	circular := false
+	for i, source := range claim.Source {
+		chain := t.follow(claim, source, visited)
+		for page := range chain.explored {
+			explored[page] = true
+		}
+		circular = circular || chain.circular
+		if i == 0 || len(chain.hops) > len(best.hops) {
+			best = chain
+		}
+	}
+	best.explored, best.circular = explored, circular
+	if !circular {
+		t.traced[key] = best
+	}
+	return best
+}
+
+// follow continues a chain from a claim into one of its sources.
+func (t *provenanceTracer) follow(claim Claim, source string, visited map[string]bool) tracedChain {
+	if verdict := verificationOf(claim, source); verdict == VerdictContradicts || verdict == VerdictNotFound {
+		return tracedChain{reason: BreakUnsupported, brokenAt: source}
+	}
+	explored := map[string]bool{source: true}
+	if visited[source] {
+		return tracedChain{reason: BreakCircular, brokenAt: source, explored: explored, circular: true}
+	}
+
+	sourcePage, scanned := t.pages[source]
+	if !scanned {
+		if t.failed[source] {
+			return tracedChain{reason: BreakDeadLink, brokenAt: source, explored: explored}
+		}
+		return tracedChain{reason: BreakNotScanned, brokenAt: source, explored: explored}
+	}
+
+	// Find the claim on the source that states the same fact, preferring the quote found during verification
+	match, similarity := bestMatch(sourcePage.Claims, claim, quoteOf(claim, source))
+	if match == nil {
+		// A source verified to back the fact without repeating it as a claim of its own is the origin
+		if verdict := verificationOf(claim, source); verdict == VerdictSupports || verdict == VerdictPartiallySupports {
+			return tracedChain{hops: []ProvenanceHop{{Page: source}}, explored: explored}
+		}
+		return tracedChain{reason: BreakUnsupported, brokenAt: source, explored: explored}
+	}
+
+	visited[source] = true
+	defer delete(visited, source)
+	chain := t.trace(source, *match, visited)
+	for page := range chain.explored {
+		explored[page] = true
+	}
+	return tracedChain{
+		hops:     append([]ProvenanceHop{{Page: source, Claim: match.Claim, Similarity: similarity}}, chain.hops...),
+		reason:   chain.reason,
+		brokenAt: chain.brokenAt,
+		explored: explored,
+		circular: chain.circular,
+	}
+}
+
+// overlaps reports whether any page of a set is on the current chain.
+func overlaps(pages, visited map[string]bool) bool {
+	for page := range pages {
+		if visited[page] {
+			return true
+		}
+	}
+	return false
+}
+
+// bestMatch returns the claim most similar to the claim or the verified quote. Claims of the same cluster always
+// match, preferring the most similar of them; otherwise the similarity must reach the threshold.
+func bestMatch(claims []Claim, claim Claim, quote string) (*Claim, float64) {
+	var best *Claim
+	bestSimilarity, bestClustered := 0.0, false
+	for i := range claims {
+		similarity := claimSimilarity(claim.Claim, claims[i].Claim)
+		if quote != "" {
+			if s := claimSimilarity(quote, claims[i].Claim); s > similarity {
+				similarity = s
+			}
+		}
+		clustered := claim.ClusterID != 0 && claims[i].ClusterID == claim.ClusterID
+		if !clustered && (bestClustered || similarity < provenanceThreshold) {
+			continue
+		}
+		if best == nil || (clustered && !bestClustered) || similarity > bestSimilarity {
+			best, bestSimilarity, bestClustered = &claims[i], similarity, clustered
+		}
+	}
+	return best, bestSimilarity
+}
+
+// verificationOf returns the verdict for a source of the claim, if it was verified.
+func verificationOf(claim Claim, source string) Verdict {
+	for _, record := range claim.Details {
+		if record.URL == source && record.Verification != nil {
+			return record.Verification.Verdict
+		}
+	}
+	return ""
+}
+
+// quoteOf returns the quote from a source that verification based its verdict on, if any.
+func quoteOf(claim Claim, source string) string {
+	for _, record := range claim.Details {
+		if record.URL == source && record.Verification != nil {
+			return record.Verification.Quote
+		}
+	}
+	return ""
+}
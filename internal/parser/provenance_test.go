@@ -0,0 +1,137 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestTraceClaim verifies how chains are followed and where they break.
+func TestTraceClaim(t *testing.T) {
+	aggregatedClaims := &AggregatedClaims{
+		RootPage: "root",
+		AllClaims: []ParsedClaims{
+			{Page: "root", Claims: []Claim{
+				{Claim: "The bridge opened in 1932 after six years of work.", Source: []string{"news", "dead"}},
+				{Claim: "The bridge is painted grey.", Source: []string{"unscanned"}},
+				{Claim: "The bridge carries eight lanes.", Source: []string{"loop"}},
+			}},
+			{Page: "news", Claims: []Claim{
+				{Claim: "After six years of work, the bridge opened in 1932.", Source: []string{"archive"}},
+			}},
+			{Page: "archive", Claims: []Claim{
+				{Claim: "The bridge was opened in 1932, six years after work began.", Source: []string{"study"}},
+			}},
+			{Page: "study", Claims: []Claim{
+				{Claim: "Traffic volumes doubled within a decade.", Source: []string{}},
+			}},
+			{Page: "loop", Claims: []Claim{
+				{Claim: "The bridge carries eight lanes of traffic.", Source: []string{"root"}},
+			}},
+		},
+		Errors: []string{"Error parsing dead: failed to scrape the page: unexpected HTTP status: 404 Not Found"},
+		failed: map[string]bool{"dead": true},
+	}
+	aggregatedClaims.AllClaims[2].Claims[0].Details = []SourceRecord{{URL: "study", Verification: &Verification{Verdict: VerdictSupports}}}
+
+	tests := []struct {
+		claim    int
+		origin   string
+		hops     int
+		reason   string
+		brokenAt string
+	}{
+		{0, "study", 3, "", ""},
+		{1, "root", 0, BreakNotScanned, "unscanned"},
+		{2, "loop", 1, BreakCircular, "root"},
+	}
+	for _, tt := range tests {
+		claim := aggregatedClaims.AllClaims[0].Claims[tt.claim]
+		p := TraceClaim(aggregatedClaims, "root", claim)
+		if p.Origin != tt.origin || p.Hops != tt.hops || p.Break != tt.reason || p.BrokenAt != tt.brokenAt {
+			t.Errorf("Claim %q: expected origin %s after %d hops with break %q at %q, got %+v", claim.Claim, tt.origin, tt.hops, tt.reason, tt.brokenAt, p)
+		}
+	}
+
+	// A dead source on its own breaks the chain as a dead link
+	dead := Claim{Claim: "The bridge opened in 1932.", Source: []string{"dead"}}
+	if p := TraceClaim(aggregatedClaims, "root", dead); p.Break != BreakDeadLink || p.BrokenAt != "dead" {
+		t.Errorf("Expected a dead link break, got %+v", p)
+	}
+}
+
+// TestTraceProvenance verifies tracing the claims of the fixture root page through the recorded scan.
+func TestTraceProvenance(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+
+	provenance, err := TraceProvenance(rootFixtureURL, 1, append(replayOptions(t), WithVerification())...)
+	if err != nil {
+		t.Fatalf("Error tracing provenance: %v", err)
+	}
+	if len(provenance) != 5 {
+		t.Fatalf("Expected provenance for 5 claims, got %d", len(provenance))
+	}
+
+	first := provenance[0]
+	if first.Origin != "https://example.org/papers/fukuyama" || first.Hops != 1 || first.Break != BreakUnsupported || first.BrokenAt != "https://example.net/survey" {
+		t.Errorf("Unexpected provenance for the first claim: %+v", first)
+	}
+	if unsourced := provenance[2]; unsourced.Hops != 0 || unsourced.Break != BreakUnsourced {
+		t.Errorf("Expected the unsourced claim to stop at the root page, got %+v", unsourced)
+	}
+}
+
+// TestTraceClaimClusters verifies that claims grouped into the same cluster are followed even when their wording differs.
+func TestTraceClaimClusters(t *testing.T) {
+	aggregatedClaims := &AggregatedClaims{
+		RootPage: "root",
+		AllClaims: []ParsedClaims{
+			{Page: "root", Claims: []Claim{
+				{Claim: "Most adults sleep less than they should.", Source: []string{"survey"}, ClusterID: 1},
+			}},
+			{Page: "survey", Claims: []Claim{
+				{Claim: "The majority of grown-ups get insufficient rest.", Source: []string{}, ClusterID: 1},
+				{Claim: "Most adults sleep in a bed.", Source: []string{}, ClusterID: 2},
+			}},
+		},
+	}
+
+	p := TraceClaim(aggregatedClaims, "root", aggregatedClaims.AllClaims[0].Claims[0])
+	if p.Origin != "survey" || p.Hops != 1 || p.Chain[1].Claim != "The majority of grown-ups get insufficient rest." {
+		t.Errorf("Expected the clustered claim on the survey to be followed, got %+v", p)
+	}
+}
+
+// TestTraceOrderIndependent verifies that a chain followed once is reused only where following it again would
+// give the same result, so that tracing does not depend on the order in which claims are traced.
+func TestTraceOrderIndependent(t *testing.T) {
+	fact := "The river floods every spring."
+	aggregatedClaims := &AggregatedClaims{
+		AllClaims: []ParsedClaims{
+			{Page: "X", Claims: []Claim{{Claim: fact, Source: []string{"B", "Z"}}}},
+			{Page: "B", Claims: []Claim{{Claim: fact, Source: []string{"X"}}}},
+			{Page: "Y", Claims: []Claim{{Claim: fact, Source: []string{"B", "W"}}}},
+		},
+	}
+	x, y := aggregatedClaims.AllClaims[0].Claims[0], aggregatedClaims.AllClaims[2].Claims[0]
+
+	fresh := newProvenanceTracer(aggregatedClaims).follow(y, "B", map[string]bool{"Y": true})
+
+	shared := newProvenanceTracer(aggregatedClaims)
+	shared.trace("X", x, map[string]bool{"X": true})
+	after := shared.follow(y, "B", map[string]bool{"Y": true})
+
+	if !reflect.DeepEqual(fresh.hops, after.hops) || fresh.reason != after.reason || fresh.brokenAt != after.brokenAt {
+		t.Errorf("Expected the same chain in either order, got %+v and %+v", fresh, after)
+	}
+	if len(fresh.hops) != 2 || fresh.hops[1].Page != "X" || fresh.reason != BreakCircular || fresh.brokenAt != "B" {
+		t.Errorf("Expected the chain B, X to stop as circular at B, got %+v", fresh)
+	}
+
+	// Tracing X again after Y must not reuse a chain that runs back through X
+	again := shared.trace("X", x, map[string]bool{"X": true})
+	if want := newProvenanceTracer(aggregatedClaims).trace("X", x, map[string]bool{"X": true}); !reflect.DeepEqual(again.hops, want.hops) || again.reason != want.reason {
+		t.Errorf("Expected the same chain from X after tracing Y, got %+v and %+v", again, want)
+	}
+}
@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// FlagNotOnPage marks a claim whose text could not be found on the page it was extracted from.
+const FlagNotOnPage = "not_on_page"
+
+// DefaultQuoteThreshold is the minimum quote score a claim needs to count as found on its page.
+const DefaultQuoteThreshold = 0.8
+
+// referenceMarker matches inline markers such as "[12]", "[a]" or "[citation needed]".
+var referenceMarker = regexp.MustCompile(`\[[^\[\]]{1,30}\]`)
+
+// ellipsis matches the ellipses that separate quoted fragments of a sentence.
+var ellipsis = regexp.MustCompile(`\.\.\.+|…`)
+
+// quoteMatcher scores how much of a claim appears verbatim in the text of a page.
+type quoteMatcher struct {
+	trigrams map[string]bool
+	text     string
+}
+
+// newQuoteMatcher indexes the scraped text of a page.
+func newQuoteMatcher(pageText string) *quoteMatcher {
+	tokens := quoteTokens(pageText)
+	m := &quoteMatcher{
+		trigrams: make(map[string]bool, len(tokens)),
+		text:     " " + strings.Join(tokens, " ") + " ",
+	}
+	for i := 0; i+3 <= len(tokens); i++ {
+		m.trigrams[strings.Join(tokens[i:i+3], " ")] = true
+	}
+	return m
+}
+
+// score returns the share of the claim's word trigrams that also appear on the page, ignoring
+// case, whitespace, punctuation, reference markers and the ellipses between quoted fragments.
+// A verbatim quote scores 1 and a paraphrase scores close to 0.
+func (m *quoteMatcher) score(claim string) float64 {
+	found, total := 0, 0
+	for _, fragment := range ellipsis.Split(claim, -1) {
+		tokens := quoteTokens(fragment)
+		switch {
+		case len(tokens) == 0:
+			continue
+		case len(tokens) < 3:
+			// Too short for trigrams, so look for the fragment as a whole
+			total++
+			if strings.Contains(m.text, " "+strings.Join(tokens, " ")+" ") {
+				found++
+			}
+		default:
+			for i := 0; i+3 <= len(tokens); i++ {
+				total++
+				if m.trigrams[strings.Join(tokens[i:i+3], " ")] {
+					found++
+				}
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(found) / float64(total)
+}
+
+// quoteTokens lowercases text and splits it into words after removing reference markers.
+func quoteTokens(text string) []string {
+	text = referenceMarker.ReplaceAllString(strings.ToLower(text), " ")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '%'
+	})
+}
+
+// checkQuotes scores every claim against the page text, flagging claims below the threshold or
+// dropping them when dropUnmatched is set. It returns the claims that are kept and how many were dropped.
+func checkQuotes(claims []Claim, pageText string, threshold float64, dropUnmatched bool) ([]Claim, int) {
+	matcher := newQuoteMatcher(pageText)
+	kept := claims[:0]
+	dropped := 0
+	for _, claim := range claims {
+		claim.QuoteScore = matcher.score(claim.Claim)
+		if claim.QuoteScore < threshold {
+			if dropUnmatched {
+				dropped++
+				continue
+			}
+			claim.addFlag(FlagNotOnPage)
+		}
+		kept = append(kept, claim)
+	}
+	return kept, dropped
+}
+
+// addFlag marks the claim with a flag, once.
+func (c *Claim) addFlag(flag string) {
+	for _, existing := range c.Flags {
+		if existing == flag {
+			return
+		}
+	}
+	c.Flags = append(c.Flags, flag)
+}
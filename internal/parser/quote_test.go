@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"testing"
+)
+
+// TestQuoteMatcherScore verifies that verbatim quotes score high and paraphrases score low.
+func TestQuoteMatcherScore(t *testing.T) {
+	page := `Go is a statically typed,   compiled high-level programming language[3] designed at Google.[12]
+		It is syntactically similar to C, but also has memory safety and garbage collection.[14][15]`
+	matcher := newQuoteMatcher(page)
+
+	tests := []struct {
+		claim string
+		min   float64
+		max   float64
+	}{
+		{"Go is a statically typed, compiled high-level programming language designed at Google.[12]", 1, 1},
+		{"... syntactically similar to C ... memory safety and garbage collection.[14]", 1, 1},
+		{"GO IS A STATICALLY TYPED, COMPILED HIGH-LEVEL PROGRAMMING LANGUAGE", 1, 1},
+		{"Go is a statically typed, compiled language created by Google engineers.", 0.2, 0.6},
+		{"Rust guarantees thread safety through its ownership model.", 0, 0},
+		{"", 0, 0},
+	}
+
+	for _, tt := range tests {
+		got := matcher.score(tt.claim)
+		if got < tt.min || got > tt.max {
+			t.Errorf("Expected score of %q to be within [%.1f, %.1f], got %.2f", tt.claim, tt.min, tt.max, got)
+		}
+	}
+}
+
+// TestParsePageClaimsQuoteCheck verifies that claims that are not on the page are flagged, or dropped when requested.
+func TestParsePageClaimsQuoteCheck(t *testing.T) {
+	parsedClaims, err := ParsePageClaims(rootFixtureURL, replayOptions(t)...)
+	if err != nil {
+		t.Fatalf("Error parsing claims: %v", err)
+	}
+	flagged := 0
+	for _, claim := range parsedClaims.Claims {
+		if hasFlag(claim, FlagNotOnPage) {
+			flagged++
+			if claim.QuoteScore >= DefaultQuoteThreshold {
+				t.Errorf("Expected flagged claim %q to score below the threshold, got %.2f", claim.Claim, claim.QuoteScore)
+			}
+		} else if claim.QuoteScore != 1 {
+			t.Errorf("Expected verbatim claim %q to score 1, got %.2f", claim.Claim, claim.QuoteScore)
+		}
+	}
+	if flagged != 1 {
+		t.Errorf("Expected 1 claim to be flagged as not on the page, got %d", flagged)
+	}
+
+	parsedClaims, err = ParsePageClaims(rootFixtureURL, append(replayOptions(t), WithDropUnmatchedQuotes())...)
+	if err != nil {
+		t.Fatalf("Error parsing claims: %v", err)
+	}
+	if len(parsedClaims.Claims) != 4 || parsedClaims.Dropped != 1 {
+		t.Errorf("Expected 4 claims to be kept and 1 dropped, got %d and %d", len(parsedClaims.Claims), parsedClaims.Dropped)
+	}
+}
+
+// hasFlag reports whether the claim carries the flag.
+func hasFlag(claim Claim, flag string) bool {
+	for _, f := range claim.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"citation-scanner/pkg/bibliography"
+	"citation-scanner/pkg/webscraper"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// footnoteMarker matches the numbered footnote markers, such as "[3]", that tie a claim to a reference list entry.
+var footnoteMarker = regexp.MustCompile(`\[(\d+)\]`)
+
+// attachReferences copies the bibliographic record, archive link, access date and dead status of the page's
+// reference list entries to the records of the sources they cite. A source matches an entry by its live URL or
+// by its archive link. Entries without any link, such as books and print journals, are added to the claims
+// whose footnote markers point at them. The bibliography of the whole reference list is returned.
+func attachReferences(claims []Claim, references []webscraper.Reference) []bibliography.Item {
+	items := make([]bibliography.Item, len(references))
+	byURL := make(map[string]int)
+	byIndex := make(map[int]int)
+	for i, reference := range references {
+		items[i] = bibliography.FromReference(reference)
+		byIndex[reference.Index] = i
+		for _, u := range []string{reference.URL, reference.ArchiveURL} {
+			if key := referenceKey(u); key != "" {
+				if _, ok := byURL[key]; !ok {
+					byURL[key] = i
+				}
+			}
+		}
+	}
+
+	for i := range claims {
+		claim := &claims[i]
+		for _, source := range claim.Source {
+			r, ok := byURL[referenceKey(source)]
+			if !ok {
+				continue
+			}
+			reference := references[r]
+			record := claim.sourceRecord(source)
+			record.Bibliography = &items[r]
+			record.ArchiveURL = reference.ArchiveURL
+			record.AccessDate = timePtr(reference.AccessDate)
+			record.MarkedDead = reference.Dead
+		}
+
+		// Sources without a URL are only known by the footnotes of the claim
+		for _, match := range footnoteMarker.FindAllStringSubmatch(claim.Claim, -1) {
+			index, _ := strconv.Atoi(match[1])
+			r, ok := byIndex[index]
+			if !ok || references[r].URL != "" || references[r].ArchiveURL != "" || claim.citesReference(items[r].ID) {
+				continue
+			}
+			claim.Details = append(claim.Details, SourceRecord{Bibliography: &items[r]})
+		}
+	}
+	return items
+}
+
+// citesReference reports whether the claim already has a record for the bibliography item with the given ID.
+func (c *Claim) citesReference(id string) bool {
+	for _, record := range c.Details {
+		if record.Bibliography != nil && record.Bibliography.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPrintSource reports whether the claim cites a reference list entry that has no URL, such as a book.
+func (c *Claim) hasPrintSource() bool {
+	for _, record := range c.Details {
+		if record.URL == "" && record.Bibliography != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveHint is what a citing page tells about the archived copies of a source: the archive link to prefer when
+// it marked the source as dead, and the date it accessed the source, around which to look for a copy in an archive.
+type archiveHint struct {
+	url      string
+	accessed time.Time
+}
+
+// sourceArchive returns what the claim's reference list entry tells about the archived copies of a source.
+func (c *Claim) sourceArchive(source string) archiveHint {
+	var hint archiveHint
+	for _, record := range c.Details {
+		if record.URL != source {
+			continue
+		}
+		if record.MarkedDead {
+			hint.url = record.ArchiveURL
+		}
+		if record.AccessDate != nil {
+			hint.accessed = *record.AccessDate
+		}
+	}
+	return hint
+}
+
+// referenceKey normalizes a URL for matching sources to reference entries, ignoring the scheme and a trailing slash.
+func referenceKey(u string) string {
+	u = strings.TrimPrefix(strings.TrimPrefix(u, "https://"), "http://")
+	return strings.TrimSuffix(u, "/")
+}
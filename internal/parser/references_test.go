@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"citation-scanner/pkg/bibliography"
+	"strings"
+	"testing"
+)
+
+// TestParsePageClaimsReferences verifies that the archive links, access dates and dead status of the reference list reach the sources.
+func TestParsePageClaimsReferences(t *testing.T) {
+	parsedClaims, err := ParsePageClaims(rootFixtureURL, replayOptions(t)...)
+	if err != nil {
+		t.Fatalf("Error parsing claims: %v", err)
+	}
+
+	fukuyama := parsedClaims.Claims[0].sourceRecord("https://example.org/papers/fukuyama")
+	if fukuyama.AccessDate == nil || fukuyama.AccessDate.Format("2006-01-02") != "2017-11-02" || fukuyama.MarkedDead || fukuyama.ArchiveURL != "" {
+		t.Errorf("Expected the live source to be accessed on 2017-11-02, got %+v", fukuyama)
+	}
+	survey := parsedClaims.Claims[1].sourceRecord("https://example.net/survey")
+	if !survey.MarkedDead || survey.ArchiveURL != "https://web.archive.org/web/20210310000000/https://example.net/survey" {
+		t.Errorf("Expected the survey to be marked dead with its archive link, got %+v", survey)
+	}
+}
+
+// TestParsePageClaimsBibliography verifies that reference list entries become bibliographic records, including the book cited without a URL.
+func TestParsePageClaimsBibliography(t *testing.T) {
+	parsedClaims, err := ParsePageClaims(rootFixtureURL, replayOptions(t)...)
+	if err != nil {
+		t.Fatalf("Error parsing claims: %v", err)
+	}
+	if len(parsedClaims.Bibliography) != 3 {
+		t.Fatalf("Expected 3 bibliography items, got %+v", parsedClaims.Bibliography)
+	}
+
+	fukuyama := parsedClaims.Claims[0].sourceRecord("https://example.org/papers/fukuyama").Bibliography
+	if fukuyama == nil || fukuyama.ID != "cite_note-1" || len(fukuyama.Author) != 1 || fukuyama.Author[0].Family != "Fukuyama" || fukuyama.Issued.Year() != 1995 {
+		t.Errorf("Expected Fukuyama (1995) as the record of the first source, got %+v", fukuyama)
+	}
+
+	claim := parsedClaims.Claims[2]
+	if len(claim.Details) != 1 || claim.Details[0].URL != "" || claim.Details[0].Bibliography == nil {
+		t.Fatalf("Expected the book without a URL to be attached to %q, got %+v", claim.Claim, claim.Details)
+	}
+	book := claim.Details[0].Bibliography
+	if book.Type != bibliography.TypeBook || book.ISBN != "9780684832838" || book.Publisher != "Simon & Schuster" || !strings.HasPrefix(book.Title, "Bowling Alone") {
+		t.Errorf("Expected Putnam's book, got %+v", book)
+	}
+	if hasFlag(claim, FlagUnsourced) {
+		t.Errorf("Expected a claim citing a book not to be flagged as unsourced, got %v", claim.Flags)
+	}
+}
+
+// TestParseAndAggregateClaimsPreferArchive verifies that a source marked dead is scanned from its archived copy without trying the live page.
+func TestParseAndAggregateClaimsPreferArchive(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+	fetch := func(url string) (string, error) {
+		if url == "https://example.net/survey" {
+			t.Errorf("Expected the dead survey not to be fetched live")
+		}
+		return fixtureFetcher(url)
+	}
+
+	aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, 1, append(replayOptions(t), WithFetcher(fetch), WithVerification())...)
+	if err != nil {
+		t.Fatalf("Error aggregating claims: %v", err)
+	}
+	for _, page := range aggregatedClaims.AllClaims {
+		if page.Page == "https://example.net/survey" && (page.Archived == nil || page.Archived.Timestamp.Format("2006-01-02") != "2021-03-10") {
+			t.Errorf("Expected the survey to be scanned from its 2021-03-10 snapshot, got %+v", page.Archived)
+		}
+	}
+	record := aggregatedClaims.AllClaims[0].Claims[1].sourceRecord("https://example.net/survey")
+	if record.Archive == nil || record.Verification == nil || record.Verification.Verdict != VerdictSupports {
+		t.Errorf("Expected the survey to be verified from its archived copy, got %+v", record)
+	}
+}
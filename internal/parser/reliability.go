@@ -0,0 +1,26 @@
+package parser
+
+import (
+	"citation-scanner/internal/reliability"
+)
+
+// assessSources attaches the category and reliability score of every source to the claims, using the page
+// signals of the sources that were fetched, and scores each claim by its most reliable source.
+// Unsourced claims score zero.
+func assessSources(claims []Claim, reputation *reliability.Reputation, fetched map[string]*fetchedPage) {
+	for i := range claims {
+		claim := &claims[i]
+		claim.Reliability = 0
+		for _, source := range claim.Source {
+			var signals *reliability.Signals
+			if page, ok := fetched[source]; ok {
+				signals = page.signals
+			}
+			assessment := reputation.Assess(source, signals)
+			claim.sourceRecord(source).Reliability = &assessment
+			if assessment.Score > claim.Reliability {
+				claim.Reliability = assessment.Score
+			}
+		}
+	}
+}
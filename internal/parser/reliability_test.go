@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"citation-scanner/internal/reliability"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseAndAggregateClaimsReliability verifies that sources are rated by their domain and by the signals of their fetched pages.
+func TestParseAndAggregateClaimsReliability(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+
+	aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, 1, replayOptions(t)...)
+	if err != nil {
+		t.Fatalf("Error aggregating claims: %v", err)
+	}
+
+	root := aggregatedClaims.AllClaims[0]
+	want := map[string]reliability.Category{
+		"https://example.org/papers/fukuyama": reliability.PeerReviewed,
+		"https://example.net/survey":          reliability.Unknown,
+	}
+	for _, claim := range root.Claims {
+		if len(claim.Source) == 0 {
+			if claim.Reliability != 0 {
+				t.Errorf("Expected unsourced claim %q to score 0, got %.2f", claim.Claim, claim.Reliability)
+			}
+			continue
+		}
+		record := claim.sourceRecord(claim.Source[0])
+		if record.Reliability == nil || record.Reliability.Category != want[record.URL] {
+			t.Errorf("Expected source %s to be rated %s, got %+v", record.URL, want[record.URL], record.Reliability)
+			continue
+		}
+		if claim.Reliability != record.Reliability.Score {
+			t.Errorf("Expected claim %q to score %.2f like its only source, got %.2f", claim.Claim, record.Reliability.Score, claim.Reliability)
+		}
+	}
+}
+
+// TestParsePageClaimsReputation verifies that a custom reputation list rates the sources of a single page.
+func TestParsePageClaimsReputation(t *testing.T) {
+	reputation, err := reliability.ParseReputation(strings.NewReader("example.net government 0.75"))
+	if err != nil {
+		t.Fatalf("Error parsing reputation: %v", err)
+	}
+	parsedClaims, err := ParsePageClaims(rootFixtureURL, append(replayOptions(t), WithReputation(reputation))...)
+	if err != nil {
+		t.Fatalf("Error parsing claims: %v", err)
+	}
+	if claim := parsedClaims.Claims[1]; claim.Reliability != 0.75 {
+		t.Errorf("Expected the claim citing example.net to score 0.75, got %.2f", claim.Reliability)
+	}
+}
+
+// TestParseAndAggregateClaimsReliabilityCached verifies that sources served from the cache are rated the same as
+// when their pages were fetched.
+func TestParseAndAggregateClaimsReliabilityCached(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+
+	var runs [2]*AggregatedClaims
+	for i := range runs {
+		aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, 1, replayOptions(t)...)
+		if err != nil {
+			t.Fatalf("Error aggregating claims: %v", err)
+		}
+		runs[i] = aggregatedClaims
+	}
+
+	cold, warm := runs[0].AllClaims[0].Claims, runs[1].AllClaims[0].Claims
+	for i := range cold {
+		for _, source := range cold[i].Source {
+			before, after := cold[i].sourceRecord(source).Reliability, warm[i].sourceRecord(source).Reliability
+			if before == nil || after == nil || !reflect.DeepEqual(before, after) {
+				t.Errorf("Expected source %s to be rated the same from the cache, got %+v and %+v", source, before, after)
+			}
+		}
+	}
+	if record := cold[0].sourceRecord("https://example.org/papers/fukuyama"); record.Reliability.Category != reliability.PeerReviewed {
+		t.Errorf("Expected the Fukuyama paper to be rated peer reviewed, got %+v", record.Reliability)
+	}
+}
@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"citation-scanner/internal/retraction"
+	"citation-scanner/pkg/identifier"
+)
+
+// FlagRetractedSource marks a claim that cites a publication found in the retraction database.
+const FlagRetractedSource = "retracted_source"
+
+// checkRetractions looks every source up in the retraction database, by the DOI or PubMed ID of the source or of its
+// reference list entry, and by the DOI, PubMed ID and title declared on its page when it was fetched, and flags the
+// claims citing retracted work. Sources without a URL are looked up by the identifiers of their reference list entry.
+func checkRetractions(claims []Claim, db *retraction.Database, fetched map[string]*fetchedPage) {
+	for i := range claims {
+		claim := &claims[i]
+		for _, source := range claim.Source {
+			var ids retraction.Identifiers
+			if id, ok := identifier.FromURL(source); ok {
+				ids = retractionIdentifiers(&id)
+			}
+			record, found := db.Lookup(ids)
+			if id := identifierOf(*claim, source); id != nil && !found {
+				record, found = db.Lookup(retractionIdentifiers(id))
+			}
+			if page, ok := fetched[source]; ok && !found {
+				record, found = db.Lookup(page.identifiers)
+			}
+			if !found {
+				continue
+			}
+			claim.sourceRecord(source).Retraction = record
+			claim.addFlag(FlagRetractedSource)
+		}
+		for j := range claim.Details {
+			if claim.Details[j].URL != "" {
+				continue
+			}
+			if record, found := db.Lookup(retractionIdentifiers(claim.Details[j].Identifier)); found {
+				claim.Details[j].Retraction = record
+				claim.addFlag(FlagRetractedSource)
+			}
+		}
+	}
+}
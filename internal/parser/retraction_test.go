@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"citation-scanner/internal/retraction"
+	"citation-scanner/pkg/bibliography"
+	"citation-scanner/pkg/webscraper"
+	"strings"
+	"testing"
+)
+
+// testRetractions is a retraction database holding the fixture paper and a paper cited by DOI link.
+const testRetractions = `Title,OriginalPaperDOI,Reason
+Trust and the Wealth of Nations Revisited,10.5555/JSC.1995.0042,Error in Analyses
+A Retracted Study of Everything,10.1000/retracted.1,Fabrication
+`
+
+// TestParseAndAggregateClaimsRetractions verifies that claims citing a page that declares a retracted DOI are flagged.
+func TestParseAndAggregateClaimsRetractions(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+	db, err := retraction.Import(strings.NewReader(testRetractions))
+	if err != nil {
+		t.Fatalf("Error importing retractions: %v", err)
+	}
+
+	aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, 1, append(replayOptions(t), WithRetractions(db))...)
+	if err != nil {
+		t.Fatalf("Error aggregating claims: %v", err)
+	}
+
+	for _, page := range aggregatedClaims.AllClaims {
+		for _, claim := range page.Claims {
+			cited := len(claim.Source) > 0 && claim.Source[0] == "https://example.org/papers/fukuyama"
+			if hasFlag(claim, FlagRetractedSource) != cited {
+				t.Errorf("Expected claim %q on %s to be flagged: %v, got flags %v", claim.Claim, page.Page, cited, claim.Flags)
+			}
+			if cited && claim.sourceRecord(claim.Source[0]).Retraction.Reason != "Error in Analyses" {
+				t.Errorf("Expected the retraction record on claim %q, got %+v", claim.Claim, claim.sourceRecord(claim.Source[0]).Retraction)
+			}
+		}
+	}
+}
+
+// TestCheckRetractionsSourceURL verifies that a DOI in the source URL is enough to find a retraction without fetching the source.
+func TestCheckRetractionsSourceURL(t *testing.T) {
+	db, err := retraction.Import(strings.NewReader(testRetractions))
+	if err != nil {
+		t.Fatalf("Error importing retractions: %v", err)
+	}
+	claims := []Claim{
+		{Claim: "Everything is connected.", Source: []string{"https://example.com/news", "https://doi.org/10.1000/RETRACTED.1"}},
+		{Claim: "Nothing is connected.", Source: []string{"https://doi.org/10.1000/sound.2"}},
+	}
+
+	checkRetractions(claims, db, nil)
+	if !hasFlag(claims[0], FlagRetractedSource) || claims[0].sourceRecord("https://doi.org/10.1000/RETRACTED.1").Retraction == nil {
+		t.Errorf("Expected the first claim to be flagged with its retracted source, got %+v", claims[0])
+	}
+	if hasFlag(claims[1], FlagRetractedSource) {
+		t.Errorf("Expected the second claim not to be flagged, got %v", claims[1].Flags)
+	}
+}
+
+// TestCheckRetractionsIdentifiers verifies that bare identifiers and sources without a URL are looked up by their identifiers.
+func TestCheckRetractionsIdentifiers(t *testing.T) {
+	db, err := retraction.Import(strings.NewReader(testRetractions))
+	if err != nil {
+		t.Fatalf("Error importing retractions: %v", err)
+	}
+	claims := []Claim{
+		{Claim: "Everything is connected.[1]", Source: []string{"doi:10.1000/retracted.1"}},
+		{Claim: "Trust is everything.[2]", Source: []string{}, Details: []SourceRecord{{
+			Bibliography: &bibliography.Item{ID: "cite_note-2", Type: bibliography.TypeArticleJournal, DOI: "10.5555/jsc.1995.0042"},
+		}}},
+	}
+
+	identifySources(claims)
+	checkRetractions(claims, db, nil)
+	if !hasFlag(claims[0], FlagRetractedSource) {
+		t.Errorf("Expected the claim citing a bare DOI to be flagged, got %+v", claims[0])
+	}
+	if !hasFlag(claims[1], FlagRetractedSource) || claims[1].Details[0].Retraction == nil {
+		t.Errorf("Expected the claim citing a retracted article without a URL to be flagged, got %+v", claims[1])
+	}
+}
+
+// TestCheckRetractionsReferenceDOI verifies that a source linking a publisher page is found by the DOI written in
+// its reference list entry, without its page being fetched.
+func TestCheckRetractionsReferenceDOI(t *testing.T) {
+	db, err := retraction.Import(strings.NewReader(testRetractions))
+	if err != nil {
+		t.Fatalf("Error importing retractions: %v", err)
+	}
+	page := `<html><body><ol class="references"><li id="cite_note-1">Smith, J. (2020). "<a href="https://publisher.example.com/article/S0001">A Retracted Study of Everything</a>". <i>Journal of Everything</i>. doi:10.1000/retracted.1.</li></ol></body></html>`
+	references, err := webscraper.References(page, "https://en.wikipedia.org/wiki/Everything")
+	if err != nil {
+		t.Fatalf("Error reading references: %v", err)
+	}
+	claims := []Claim{{Claim: "Everything is connected.[1]", Source: []string{"https://publisher.example.com/article/S0001"}}}
+
+	attachReferences(claims, references)
+	identifySources(claims)
+	checkRetractions(claims, db, nil)
+	if !hasFlag(claims[0], FlagRetractedSource) || claims[0].sourceRecord("https://publisher.example.com/article/S0001").Retraction == nil {
+		t.Errorf("Expected the claim to be flagged by the DOI of its reference, got %+v", claims[0])
+	}
+}
+
+// TestParseAndAggregateClaimsRetractionsCached verifies that a source found only by the identifiers declared on its
+// page is still flagged when the page is served from the cache.
+func TestParseAndAggregateClaimsRetractionsCached(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+	db, err := retraction.Import(strings.NewReader(testRetractions))
+	if err != nil {
+		t.Fatalf("Error importing retractions: %v", err)
+	}
+
+	for _, run := range []string{"cold", "warm"} {
+		aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, 1, append(replayOptions(t), WithRetractions(db))...)
+		if err != nil {
+			t.Fatalf("Error aggregating claims: %v", err)
+		}
+		claim := aggregatedClaims.AllClaims[0].Claims[0]
+		if claim.Source[0] != "https://example.org/papers/fukuyama" || !hasFlag(claim, FlagRetractedSource) {
+			t.Errorf("Expected the claim citing the retracted paper to be flagged on the %s scan, got %+v", run, claim)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Scope restricts which sources a recursive scan follows. Empty fields impose no restriction.
+// Rules are evaluated in the order of the fields below and the first matching rule skips the URL.
+type Scope struct {
+	// AllowedDomains, when set, limits recursion to these domains and their subdomains.
+	AllowedDomains []string
+	// DeniedDomains are never followed, including their subdomains.
+	DeniedDomains []string
+	// AllowPatterns, when set, limits recursion to URLs matching at least one of the expressions.
+	AllowPatterns []*regexp.Regexp
+	// DenyPatterns are URL expressions that are never followed.
+	DenyPatterns []*regexp.Regexp
+	// SameSiteOnly limits recursion to the registrable domain of the root page.
+	SameSiteOnly bool
+	// AllowedFileTypes, when set, limits recursion to URLs whose path has one of these extensions.
+	// URLs without an extension are always allowed.
+	AllowedFileTypes []string
+	// DeniedFileTypes are path extensions, such as "pdf" or ".zip", that are never followed.
+	DeniedFileTypes []string
+}
+
+// check returns the rule that excludes rawURL from a scan rooted at rootURL, or an empty string if it is in scope.
+// URLs that cannot be parsed are left in scope so that fetching them reports the error.
+func (s Scope) check(rawURL, rootURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+
+	if len(s.AllowedDomains) > 0 && matchDomain(host, s.AllowedDomains) == "" {
+		return fmt.Sprintf("scope: domain %s is not in the allowed domains", host)
+	}
+	if domain := matchDomain(host, s.DeniedDomains); domain != "" {
+		return fmt.Sprintf("scope: denied domain %s", domain)
+	}
+	if len(s.AllowPatterns) > 0 && matchPattern(rawURL, s.AllowPatterns) == "" {
+		return "scope: URL does not match any allowed pattern"
+	}
+	if pattern := matchPattern(rawURL, s.DenyPatterns); pattern != "" {
+		return fmt.Sprintf("scope: denied pattern %s", pattern)
+	}
+	if s.SameSiteOnly {
+		if root, err := url.Parse(rootURL); err == nil && siteOf(host) != siteOf(strings.ToLower(root.Hostname())) {
+			return fmt.Sprintf("scope: %s is not on the same site as the root page", host)
+		}
+	}
+
+	ext := strings.TrimPrefix(strings.ToLower(path.Ext(u.Path)), ".")
+	if ext != "" && len(s.AllowedFileTypes) > 0 && !containsFileType(s.AllowedFileTypes, ext) {
+		return fmt.Sprintf("scope: file type %s is not in the allowed file types", ext)
+	}
+	if ext != "" && containsFileType(s.DeniedFileTypes, ext) {
+		return fmt.Sprintf("scope: denied file type %s", ext)
+	}
+	return ""
+}
+
+// matchDomain returns the entry of domains that host equals or is a subdomain of.
+func matchDomain(host string, domains []string) string {
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return domain
+		}
+	}
+	return ""
+}
+
+// matchPattern returns the first pattern that matches rawURL.
+func matchPattern(rawURL string, patterns []*regexp.Regexp) string {
+	for _, pattern := range patterns {
+		if pattern.MatchString(rawURL) {
+			return pattern.String()
+		}
+	}
+	return ""
+}
+
+// containsFileType reports whether ext is in the list of file types, which may be written with or without a leading dot.
+func containsFileType(fileTypes []string, ext string) bool {
+	for _, fileType := range fileTypes {
+		if strings.TrimPrefix(strings.ToLower(fileType), ".") == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// siteOf approximates the registrable domain of a host by its last two labels, or three when the
+// second-level label is a common public suffix such as "co.uk" or "com.au".
+func siteOf(host string) string {
+	labels := strings.Split(host, ".")
+	if len(labels) <= 2 {
+		return host
+	}
+	n := 2
+	switch labels[len(labels)-2] {
+	case "co", "com", "org", "net", "ac", "gov", "edu":
+		if len(labels[len(labels)-1]) == 2 {
+			n = 3
+		}
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
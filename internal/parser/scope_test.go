@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestScopeCheck verifies that each scope rule skips the expected URLs and reports the matched rule.
+func TestScopeCheck(t *testing.T) {
+	root := "https://en.wikipedia.org/wiki/Trust"
+	tests := []struct {
+		name  string
+		scope Scope
+		url   string
+		want  string
+	}{
+		{"empty scope", Scope{}, "https://twitter.com/someone", ""},
+		{"allowed domain", Scope{AllowedDomains: []string{"example.org"}}, "https://www.example.org/a", ""},
+		{"not allowed domain", Scope{AllowedDomains: []string{"example.org"}}, "https://example.com/a", "not in the allowed domains"},
+		{"denied subdomain", Scope{DeniedDomains: []string{"twitter.com"}}, "https://mobile.twitter.com/x", "denied domain twitter.com"},
+		{"allow pattern", Scope{AllowPatterns: []*regexp.Regexp{regexp.MustCompile(`/papers/`)}}, "https://example.org/blog/1", "does not match any allowed pattern"},
+		{"deny pattern", Scope{DenyPatterns: []*regexp.Regexp{regexp.MustCompile(`web\.archive\.org`)}}, "https://web.archive.org/web/2020/https://example.org", `denied pattern web\.archive\.org`},
+		{"same site", Scope{SameSiteOnly: true}, "https://de.wikipedia.org/wiki/Vertrauen", ""},
+		{"other site", Scope{SameSiteOnly: true}, "https://example.org/a", "not on the same site"},
+		{"same site public suffix", Scope{SameSiteOnly: true}, "https://news.bbc.co.uk/a", "not on the same site"},
+		{"allowed file type", Scope{AllowedFileTypes: []string{"html"}}, "https://example.org/a.pdf", "file type pdf is not in the allowed file types"},
+		{"no extension", Scope{AllowedFileTypes: []string{"html"}}, "https://example.org/a", ""},
+		{"denied file type", Scope{DeniedFileTypes: []string{".PDF"}}, "https://example.org/paper.pdf", "denied file type pdf"},
+	}
+
+	for _, tt := range tests {
+		got := tt.scope.check(tt.url, root)
+		if tt.want == "" && got != "" {
+			t.Errorf("%s: expected %s to be in scope, got %q", tt.name, tt.url, got)
+		}
+		if tt.want != "" && !strings.Contains(got, tt.want) {
+			t.Errorf("%s: expected %s to be skipped with %q, got %q", tt.name, tt.url, tt.want, got)
+		}
+	}
+}
+
+// TestParseAndAggregateClaimsScope verifies that out-of-scope sources are reported as skipped instead of scanned.
+func TestParseAndAggregateClaimsScope(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+
+	opts := append(replayOptions(t), WithScope(Scope{SameSiteOnly: true}))
+	aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, 1, opts...)
+	if err != nil {
+		t.Fatalf("Error parsing and aggregating claims: %v", err)
+	}
+
+	if len(aggregatedClaims.AllClaims) != 2 {
+		t.Errorf("Expected claims from 2 pages, got %d", len(aggregatedClaims.AllClaims))
+	}
+	if len(aggregatedClaims.Skipped) != 1 || aggregatedClaims.Skipped[0].URL != "https://example.net/survey" {
+		t.Fatalf("Expected https://example.net/survey to be skipped, got %v", aggregatedClaims.Skipped)
+	}
+	if !strings.HasPrefix(aggregatedClaims.Skipped[0].Reason, "scope:") {
+		t.Errorf("Expected a scope rule as the reason, got %q", aggregatedClaims.Skipped[0].Reason)
+	}
+}
@@ -0,0 +1,41 @@
+package parser
+
+// stopwords are left out when comparing claims, so that similarity reflects the words that carry meaning.
+var stopwords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "but": true, "of": true, "in": true,
+	"on": true, "at": true, "to": true, "for": true, "with": true, "by": true, "from": true, "as": true,
+	"is": true, "are": true, "was": true, "were": true, "be": true, "been": true, "has": true, "have": true,
+	"had": true, "that": true, "this": true, "these": true, "those": true, "it": true, "its": true,
+	"which": true, "who": true, "than": true, "their": true, "they": true, "there": true, "also": true,
+}
+
+// claimTerms returns the set of meaningful words of a claim, ignoring case, punctuation and reference markers.
+func claimTerms(claim string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, token := range quoteTokens(claim) {
+		if !stopwords[token] {
+			terms[token] = true
+		}
+	}
+	return terms
+}
+
+// claimSimilarity returns the Dice coefficient of the meaningful words of two claims,
+// from 0 for claims without common words to 1 for claims with the same words.
+func claimSimilarity(a, b string) float64 {
+	return termSimilarity(claimTerms(a), claimTerms(b))
+}
+
+// termSimilarity returns the Dice coefficient of two sets of words.
+func termSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for term := range a {
+		if b[term] {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(a)+len(b))
+}
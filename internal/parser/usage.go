@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"citation-scanner/pkg/openai"
+	"strings"
+)
+
+// ModelPrice is the price of a model in US dollars per million prompt and completion tokens.
+type ModelPrice struct {
+	PromptPerMillion     float64 `json:"prompt_per_million"`
+	CompletionPerMillion float64 `json:"completion_per_million"`
+}
+
+// DefaultPriceTable holds the list prices used when no custom price table is configured.
+var DefaultPriceTable = map[string]ModelPrice{
+	"gpt-4o":        {PromptPerMillion: 2.50, CompletionPerMillion: 10.00},
+	"gpt-4o-mini":   {PromptPerMillion: 0.15, CompletionPerMillion: 0.60},
+	"gpt-4-turbo":   {PromptPerMillion: 10.00, CompletionPerMillion: 30.00},
+	"gpt-4":         {PromptPerMillion: 30.00, CompletionPerMillion: 60.00},
+	"gpt-3.5-turbo": {PromptPerMillion: 0.50, CompletionPerMillion: 1.50},
+
+	"text-embedding-3-small": {PromptPerMillion: 0.02},
+	"text-embedding-3-large": {PromptPerMillion: 0.13},
+}
+
+// Usage represents the LLM token usage and estimated cost of a page or a whole scan.
+type Usage struct {
+	Requests         int      `json:"requests"`
+	PromptTokens     int64    `json:"prompt_tokens"`
+	CompletionTokens int64    `json:"completion_tokens"`
+	TotalTokens      int64    `json:"total_tokens"`
+	EstimatedCost    float64  `json:"estimated_cost_usd"`
+	UnpricedModels   []string `json:"unpriced_models,omitempty"`
+}
+
+// addCompletion adds the tokens of a single chat completion and their cost according to the price table.
+func (u *Usage) addCompletion(completion *openai.ChatCompletion, prices map[string]ModelPrice) {
+	u.addRequest(completion.Model, completion.Usage, prices)
+}
+
+// addRequest adds the tokens a model used for a single request and their cost according to the price table.
+func (u *Usage) addRequest(model string, tokens openai.Usage, prices map[string]ModelPrice) {
+	u.Requests++
+	u.PromptTokens += tokens.PromptTokens
+	u.CompletionTokens += tokens.CompletionTokens
+	u.TotalTokens += tokens.TotalTokens
+
+	price, ok := lookupPrice(model, prices)
+	if !ok {
+		u.addUnpriced(model)
+		return
+	}
+	u.EstimatedCost += float64(tokens.PromptTokens)*price.PromptPerMillion/1e6 +
+		float64(tokens.CompletionTokens)*price.CompletionPerMillion/1e6
+}
+
+// add accumulates another usage record, such as a page's usage into the usage of a scan.
+func (u *Usage) add(other *Usage) {
+	if other == nil {
+		return
+	}
+	u.Requests += other.Requests
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.TotalTokens += other.TotalTokens
+	u.EstimatedCost += other.EstimatedCost
+	for _, model := range other.UnpricedModels {
+		u.addUnpriced(model)
+	}
+}
+
+// addUnpriced records a model that had no entry in the price table.
+func (u *Usage) addUnpriced(model string) {
+	for _, existing := range u.UnpricedModels {
+		if existing == model {
+			return
+		}
+	}
+	u.UnpricedModels = append(u.UnpricedModels, model)
+}
+
+// lookupPrice finds the price for a model, falling back to the longest matching prefix
+// so that dated snapshots such as "gpt-4o-2024-08-06" use the price of "gpt-4o".
+func lookupPrice(model string, prices map[string]ModelPrice) (ModelPrice, bool) {
+	if price, ok := prices[model]; ok {
+		return price, true
+	}
+	var best string
+	for name := range prices {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelPrice{}, false
+	}
+	return prices[best], true
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"citation-scanner/pkg/openai"
+	"math"
+	"testing"
+)
+
+// TestUsageAddCompletion verifies that token counts are summed and priced by model, including dated snapshots.
+func TestUsageAddCompletion(t *testing.T) {
+	prices := map[string]ModelPrice{
+		"gpt-4o":      {PromptPerMillion: 2.50, CompletionPerMillion: 10.00},
+		"gpt-4o-mini": {PromptPerMillion: 0.15, CompletionPerMillion: 0.60},
+	}
+
+	var usage Usage
+	usage.addCompletion(&openai.ChatCompletion{
+		Model: "gpt-4o-2024-08-06",
+		Usage: openai.Usage{PromptTokens: 1000000, CompletionTokens: 100000, TotalTokens: 1100000},
+	}, prices)
+	usage.addCompletion(&openai.ChatCompletion{
+		Model: "gpt-4o-mini-2024-07-18",
+		Usage: openai.Usage{PromptTokens: 1000000, CompletionTokens: 0, TotalTokens: 1000000},
+	}, prices)
+	usage.addCompletion(&openai.ChatCompletion{
+		Model: "unknown-model",
+		Usage: openai.Usage{PromptTokens: 10, CompletionTokens: 10, TotalTokens: 20},
+	}, prices)
+
+	if usage.Requests != 3 || usage.TotalTokens != 2100020 {
+		t.Errorf("Expected 3 requests and 2100020 tokens, got %d and %d", usage.Requests, usage.TotalTokens)
+	}
+	if want := 2.50 + 1.00 + 0.15; math.Abs(usage.EstimatedCost-want) > 1e-9 {
+		t.Errorf("Expected estimated cost %.4f, got %.4f", want, usage.EstimatedCost)
+	}
+	if len(usage.UnpricedModels) != 1 || usage.UnpricedModels[0] != "unknown-model" {
+		t.Errorf("Expected unknown-model to be reported as unpriced, got %v", usage.UnpricedModels)
+	}
+}
+
+// TestAggregatedUsage verifies that the usage of a scan is the sum of the usage of its pages.
+func TestAggregatedUsage(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+
+	aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, 1, replayOptions(t)...)
+	if err != nil {
+		t.Fatalf("Error parsing and aggregating claims: %v", err)
+	}
+
+	var want Usage
+	for _, page := range aggregatedClaims.AllClaims {
+		if page.Usage == nil || page.Usage.TotalTokens == 0 {
+			t.Fatalf("Expected token usage for page %s", page.Page)
+		}
+		want.add(page.Usage)
+	}
+	got := aggregatedClaims.Usage
+	if got.Requests != want.Requests || got.TotalTokens != want.TotalTokens || math.Abs(got.EstimatedCost-want.EstimatedCost) > 1e-9 {
+		t.Errorf("Expected scan usage %+v, got %+v", want, got)
+	}
+	if got.EstimatedCost <= 0 {
+		t.Errorf("Expected a positive estimated cost, got %f", got.EstimatedCost)
+	}
+}
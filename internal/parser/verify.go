@@ -0,0 +1,245 @@
+package parser
+
+import (
+	"citation-scanner/internal/reliability"
+	"citation-scanner/internal/retraction"
+	"citation-scanner/pkg/archive"
+	"citation-scanner/pkg/bibliography"
+	"citation-scanner/pkg/identifier"
+	"citation-scanner/pkg/linkcheck"
+	"citation-scanner/pkg/webscraper"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Verdict is the outcome of checking a claim against one of its sources.
+type Verdict string
+
+const (
+	VerdictSupports          Verdict = "supports"
+	VerdictPartiallySupports Verdict = "partially_supports"
+	VerdictContradicts       Verdict = "contradicts"
+	VerdictNotFound          Verdict = "not_found"
+)
+
+// SourceRecord holds what is known about one source of a claim. Sources cited only by a reference list
+// entry without a link, such as books, have an empty URL and are known by their bibliographic record.
+type SourceRecord struct {
+	URL          string                  `json:"url"`
+	Identifier   *identifier.Identifier  `json:"identifier,omitempty"`
+	Bibliography *bibliography.Item      `json:"bibliography,omitempty"`
+	Reliability  *reliability.Assessment `json:"reliability,omitempty"`
+	Retraction   *retraction.Record      `json:"retraction,omitempty"`
+	Published    *time.Time              `json:"published,omitempty"`
+	Link         *linkcheck.Result       `json:"link,omitempty"`
+	Archive      *archive.Snapshot       `json:"archive,omitempty"`
+	ArchiveURL   string                  `json:"archive_url,omitempty"`
+	AccessDate   *time.Time              `json:"access_date,omitempty"`
+	MarkedDead   bool                    `json:"marked_dead,omitempty"`
+	Verification *Verification           `json:"verification,omitempty"`
+}
+
+// Verification is the LLM's verdict on whether a source backs a claim, with the quote from the source it is based on.
+type Verification struct {
+	Verdict           Verdict           `json:"verdict,omitempty"`
+	Quote             string            `json:"quote,omitempty"`
+	Explanation       string            `json:"explanation,omitempty"`
+	NumericMismatches []NumericMismatch `json:"numeric_mismatches,omitempty"`
+	Error             string            `json:"error,omitempty"`
+}
+
+// sourceRecord returns the record for a source of the claim, adding it if the claim has none yet.
+func (c *Claim) sourceRecord(url string) *SourceRecord {
+	for i := range c.Details {
+		if c.Details[i].URL == url {
+			return &c.Details[i]
+		}
+	}
+	c.Details = append(c.Details, SourceRecord{URL: url})
+	return &c.Details[len(c.Details)-1]
+}
+
+// VerifyClaims checks every claim of a parsed page against each of its sources and stores the verdicts on the claims.
+func VerifyClaims(parsedClaims *ParsedClaims, opts ...func(*Options)) error {
+	o, err := newOptions(opts...)
+	if err != nil {
+		return err
+	}
+	scan := newScanState(o)
+	pages := []ParsedClaims{*parsedClaims}
+	scan.verifyPages(pages, parsedClaims.Page, nil)
+	*parsedClaims = pages[0]
+	return nil
+}
+
+// verifyJob is a single (claim, source) pair waiting for verification.
+type verifyJob struct {
+	page, claim int
+	source      string
+}
+
+// sourceText is the scraped text of a source, fetched at most once per scan.
+type sourceText struct {
+	once sync.Once
+	text string
+	err  error
+}
+
+// verifyPages verifies every (claim, source) pair of the pages in place. Sources skipped by the scan of rootURL or
+// out of its scope are not verified. Pages fetched during the scan are reused, and other sources are fetched at most
+// once, by no more than fetchConcurrency workers, and count against the page budget. Verdicts are requested by
+// llmConcurrency workers. The token usage is added to the page and to the scan.
+func (s *scanState) verifyPages(pages []ParsedClaims, rootURL string, skipped []SkippedURL) {
+	skippedReasons := make(map[string]string)
+	for _, skip := range skipped {
+		skippedReasons[skip.URL] = skip.Reason
+	}
+
+	var jobs []verifyJob
+	hints := make(map[string]archiveHint)
+	for p := range pages {
+		for c := range pages[p].Claims {
+			for _, source := range pages[p].Claims[c].Source {
+				// Create the records up front so that workers never grow the slices concurrently
+				record := pages[p].Claims[c].sourceRecord(source)
+				reason, ok := skippedReasons[source]
+				if !ok {
+					reason = s.o.scope.check(s.o.identifiers.FetchURL(source), rootURL)
+				}
+				if reason != "" {
+					record.Verification = &Verification{Error: reason}
+					continue
+				}
+				jobs = append(jobs, verifyJob{page: p, claim: c, source: source})
+				if hint := pages[p].Claims[c].sourceArchive(source); hint.url != "" || !hint.accessed.IsZero() {
+					hints[source] = hint
+				}
+			}
+		}
+	}
+
+	var textsMu sync.Mutex
+	texts := make(map[string]*sourceText)
+	fetchSlots := make(chan struct{}, s.o.fetchConcurrency)
+	scrapeSource := func(url string) (string, error) {
+		textsMu.Lock()
+		st, ok := texts[url]
+		if !ok {
+			st = &sourceText{}
+			texts[url] = st
+		}
+		textsMu.Unlock()
+
+		st.once.Do(func() {
+			s.mu.Lock()
+			page, scanned := s.html[url]
+			s.mu.Unlock()
+			if scanned {
+				st.text, st.err = webscraper.BodyText(page)
+				return
+			}
+			if reason := s.reserve(); reason != "" {
+				st.err = fmt.Errorf("%s", reason)
+				return
+			}
+
+			fetchSlots <- struct{}{}
+			defer func() { <-fetchSlots }()
+			page, _, snapshot, err := fetchPage(url, hints[url], s.o)
+			if err != nil {
+				st.err = fmt.Errorf("failed to scrape the source: %v", err)
+				return
+			}
+			s.recordPage(url, page, snapshot)
+			st.text, st.err = webscraper.BodyText(page)
+		})
+		return st.text, st.err
+	}
+
+	queue := make(chan verifyJob)
+	var pagesMu sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < s.o.llmConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				claim := &pages[job.page].Claims[job.claim]
+				verification, usage := s.verify(claim.Claim, job.source, scrapeSource)
+
+				pagesMu.Lock()
+				claim.sourceRecord(job.source).Verification = verification
+				if len(verification.NumericMismatches) > 0 {
+					claim.addFlag(FlagNumericMismatch)
+				}
+				if usage != nil {
+					if pages[job.page].Usage == nil {
+						pages[job.page].Usage = &Usage{}
+					}
+					pages[job.page].Usage.add(usage)
+				}
+				pagesMu.Unlock()
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// verify asks the LLM whether the text of a source supports a claim, returning the verdict and the usage of the request.
+// The page budget is left to scrapeSource, which only spends it on sources that were not scanned.
+func (s *scanState) verify(claim, source string, scrapeSource func(string) (string, error)) (*Verification, *Usage) {
+	s.mu.Lock()
+	reason := s.o.budget.exhausted(0, s.usage, time.Since(s.started))
+	s.mu.Unlock()
+	if reason != "" {
+		return &Verification{Error: reason}, nil
+	}
+
+	text, err := scrapeSource(source)
+	if err != nil {
+		return &Verification{Error: err.Error()}, nil
+	}
+
+	prompt := fmt.Sprintf(`
+		You are a fact checker that verifies whether a source supports a claim made by an article that cites it.
+		Read the source content below and decide whether it supports the claim.
+		Use "supports" when the source states the claim, "partially_supports" when it states only part of it or states it with different details,
+		"contradicts" when it states something incompatible with the claim, and "not_found" when it does not address the claim.
+		The quote must be copied verbatim from the source content, and must be empty when the verdict is "not_found".
+		DO NOT wrap response with Markdown code-block formatting.
+		Respond only with a JSON object formatted as follows:
+		{"verdict": "supports", "quote": "Exact sentence from the source.", "explanation": "One sentence explaining the verdict."}
+		Claim: "%s"
+		Source content: "%s"
+	`, claim, text)
+
+	completion, err := s.o.client.Complete(prompt)
+	if err != nil {
+		return &Verification{Error: fmt.Sprintf("failed to verify claim: %v", err)}, nil
+	}
+	usage := &Usage{}
+	usage.addCompletion(completion, s.o.prices)
+	s.mu.Lock()
+	s.usage.add(usage)
+	s.mu.Unlock()
+
+	var verification Verification
+	if err := json.Unmarshal([]byte(completion.Content), &verification); err != nil {
+		return &Verification{Error: fmt.Sprintf("failed to parse verification as JSON: %v", err)}, usage
+	}
+	switch verification.Verdict {
+	case VerdictSupports, VerdictPartiallySupports, VerdictContradicts, VerdictNotFound:
+	default:
+		return &Verification{Error: fmt.Sprintf("unknown verdict %q", verification.Verdict)}, usage
+	}
+
+	// Compare the figures of the claim with the ones in the quote the verdict is based on
+	verification.NumericMismatches = compareQuantities(claim, verification.Quote)
+	return &verification, usage
+}
@@ -0,0 +1,159 @@
+package parser
+
+import (
+	"citation-scanner/pkg/openai"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestParseAndAggregateClaimsVerification verifies that every (claim, source) pair receives a verdict.
+func TestParseAndAggregateClaimsVerification(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+
+	opts := append(replayOptions(t), WithVerification())
+	aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, 1, opts...)
+	if err != nil {
+		t.Fatalf("Error parsing and aggregating claims: %v", err)
+	}
+
+	verdicts := make(map[string]Verdict)
+	for _, page := range aggregatedClaims.AllClaims {
+		for _, claim := range page.Claims {
+			linked := 0
+			for _, record := range claim.Details {
+				if record.URL != "" {
+					linked++
+				}
+			}
+			if linked != len(claim.Source) {
+				t.Errorf("Expected a source record per source for claim %q, got %d", claim.Claim, linked)
+			}
+			for _, record := range claim.Details {
+				// Sources without a URL cannot be fetched, so they are not verified
+				if record.URL == "" {
+					continue
+				}
+				if record.Verification == nil || record.Verification.Error != "" {
+					t.Fatalf("Expected a verdict for %s on claim %q, got %+v", record.URL, claim.Claim, record.Verification)
+				}
+				verdicts[page.Page+" -> "+record.URL] = record.Verification.Verdict
+			}
+		}
+	}
+
+	want := map[string]Verdict{
+		rootFixtureURL + " -> https://example.org/papers/fukuyama":          VerdictSupports,
+		rootFixtureURL + " -> https://example.net/survey":                   VerdictSupports,
+		"https://example.org/papers/fukuyama -> https://example.net/survey": VerdictNotFound,
+		"https://example.net/survey -> https://example.org/papers/fukuyama": VerdictNotFound,
+	}
+	for pair, verdict := range want {
+		if verdicts[pair] != verdict {
+			t.Errorf("Expected %s to be %q, got %q", pair, verdict, verdicts[pair])
+		}
+	}
+}
+
+// TestVerifyClaimsUnreachableSource verifies that a source that cannot be fetched is recorded as an error.
+func TestVerifyClaimsUnreachableSource(t *testing.T) {
+	parsedClaims := &ParsedClaims{
+		Page:   rootFixtureURL,
+		Claims: []Claim{{Claim: "An unreachable claim.", Source: []string{"https://example.org/missing"}}},
+	}
+	if err := VerifyClaims(parsedClaims, replayOptions(t)...); err != nil {
+		t.Fatalf("Error verifying claims: %v", err)
+	}
+
+	verification := parsedClaims.Claims[0].Details[0].Verification
+	if verification == nil || !strings.Contains(verification.Error, "failed to scrape the source") {
+		t.Errorf("Expected a scrape error, got %+v", verification)
+	}
+}
+
+// countingClient forwards prompts to a client and counts the verification requests among them.
+type countingClient struct {
+	client        openai.ChatClient
+	mu            sync.Mutex
+	verifications int
+}
+
+func (c *countingClient) Complete(prompt string) (*openai.ChatCompletion, error) {
+	if strings.Contains(prompt, "You are a fact checker") {
+		c.mu.Lock()
+		c.verifications++
+		c.mu.Unlock()
+	}
+	return c.client.Complete(prompt)
+}
+
+// TestParseAndAggregateClaimsVerificationScope verifies that sources left out of a scan by its scope or its page
+// budget are neither fetched nor sent for verification.
+func TestParseAndAggregateClaimsVerificationScope(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+	replayer, err := openai.NewReplayer("testdata/cassette.json")
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	client := &countingClient{client: replayer}
+	var mu sync.Mutex
+	fetches := make(map[string]int)
+	countingFetcher := func(url string) (string, error) {
+		mu.Lock()
+		fetches[url]++
+		mu.Unlock()
+		return fixtureFetcher(url)
+	}
+
+	opts := append(testOptions(client), WithFetcher(countingFetcher), WithVerification(),
+		WithScope(Scope{DeniedDomains: []string{"example.net"}}), WithBudget(Budget{MaxPages: 1}))
+	aggregatedClaims, err := ParseAndAggregateClaims(rootFixtureURL, 1, opts...)
+	if err != nil {
+		t.Fatalf("Error parsing and aggregating claims: %v", err)
+	}
+
+	if len(fetches) != 1 || fetches[rootFixtureURL] != 1 {
+		t.Errorf("Expected only the root page to be fetched, got %v", fetches)
+	}
+	if client.verifications != 0 {
+		t.Errorf("Expected no verification requests, got %d", client.verifications)
+	}
+	want := map[string]string{"https://example.org/papers/fukuyama": "max pages", "https://example.net/survey": "scope:"}
+	for _, claim := range aggregatedClaims.AllClaims[0].Claims {
+		for _, source := range claim.Source {
+			verification := claim.sourceRecord(source).Verification
+			if verification == nil || !strings.Contains(verification.Error, want[source]) {
+				t.Errorf("Expected %s not to be verified because of %q, got %+v", source, want[source], verification)
+			}
+		}
+	}
+}
+
+// TestParseAndAggregateClaimsVerificationReusesPages verifies that sources scanned in the same run are not fetched again.
+func TestParseAndAggregateClaimsVerificationReusesPages(t *testing.T) {
+	if err := initTestCache(t); err != nil {
+		t.Fatalf("Failed to initialize cache: %v", err)
+	}
+	var mu sync.Mutex
+	fetches := make(map[string]int)
+	countingFetcher := func(url string) (string, error) {
+		mu.Lock()
+		fetches[url]++
+		mu.Unlock()
+		return fixtureFetcher(url)
+	}
+
+	opts := append(replayOptions(t), WithFetcher(countingFetcher), WithVerification())
+	if _, err := ParseAndAggregateClaims(rootFixtureURL, 1, opts...); err != nil {
+		t.Fatalf("Error parsing and aggregating claims: %v", err)
+	}
+	for url, count := range fetches {
+		if count != 1 {
+			t.Errorf("Expected %s to be fetched once, got %d", url, count)
+		}
+	}
+}
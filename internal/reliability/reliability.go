@@ -0,0 +1,172 @@
+package reliability
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Category is the kind of publication a source is.
+type Category string
+
+// Categories of sources.
+const (
+	PeerReviewed  Category = "peer_reviewed"
+	Government    Category = "government"
+	News          Category = "news"
+	Preprint      Category = "preprint"
+	Blog          Category = "blog"
+	Social        Category = "social"
+	UserGenerated Category = "user_generated"
+	Unknown       Category = "unknown"
+)
+
+// DefaultScores is the reliability score, between 0 and 1, of each category when the reputation file does not override it.
+var DefaultScores = map[Category]float64{
+	PeerReviewed:  0.9,
+	Government:    0.85,
+	News:          0.7,
+	Preprint:      0.55,
+	Unknown:       0.5,
+	Blog:          0.35,
+	UserGenerated: 0.3,
+	Social:        0.2,
+}
+
+// signalBonus is added to the score of a source for each sign of editorial accountability on its page.
+const signalBonus = 0.05
+
+//go:embed reputation.txt
+var defaultReputationList string
+
+var (
+	defaultReputationOnce sync.Once
+	defaultReputation     *Reputation
+)
+
+// Assessment is the category and reliability score given to a source, with the page signals that contributed to it.
+type Assessment struct {
+	Category Category `json:"category"`
+	Score    float64  `json:"score"`
+	Signals  []string `json:"signals,omitempty"`
+}
+
+// rule is a line of the reputation file.
+type rule struct {
+	category Category
+	score    float64
+}
+
+// Reputation maps domains to the category and score of the sources they host.
+type Reputation struct {
+	rules map[string]rule
+}
+
+// DefaultReputation returns the reputation list shipped with the scanner. It is parsed once and shared.
+func DefaultReputation() *Reputation {
+	defaultReputationOnce.Do(func() {
+		reputation, err := ParseReputation(strings.NewReader(defaultReputationList))
+		if err != nil {
+			panic(fmt.Sprintf("invalid default reputation list: %v", err))
+		}
+		defaultReputation = reputation
+	})
+	return defaultReputation
+}
+
+// LoadReputation reads a reputation file, such as an edited copy of the default reputation.txt.
+func LoadReputation(path string) (*Reputation, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reputation file: %v", err)
+	}
+	defer file.Close()
+	return ParseReputation(file)
+}
+
+// ParseReputation reads a reputation list of "domain category [score]" lines. Blank lines and lines starting with # are ignored.
+func ParseReputation(r io.Reader) (*Reputation, error) {
+	reputation := &Reputation{rules: make(map[string]rule)}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("line %d: expected a domain, a category and an optional score", line)
+		}
+
+		category := Category(strings.ToLower(fields[1]))
+		score, ok := DefaultScores[category]
+		if !ok || category == Unknown {
+			return nil, fmt.Errorf("line %d: unknown category %q", line, fields[1])
+		}
+		if len(fields) == 3 {
+			var err error
+			score, err = strconv.ParseFloat(fields[2], 64)
+			if err != nil || score < 0 || score > 1 {
+				return nil, fmt.Errorf("line %d: score %q is not a number between 0 and 1", line, fields[2])
+			}
+		}
+		reputation.rules[strings.Trim(strings.ToLower(fields[0]), ".")] = rule{category: category, score: score}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read reputation list: %v", err)
+	}
+	return reputation, nil
+}
+
+// Assess classifies a source by its domain and, when its page was fetched, by the signals found on the page.
+// The domain takes precedence over the page signals for the category.
+func (r *Reputation) Assess(rawURL string, signals *Signals) Assessment {
+	assessment := Assessment{Category: Unknown, Score: DefaultScores[Unknown]}
+	matched := false
+	if parsed, err := url.Parse(rawURL); err == nil {
+		if rule, ok := r.lookup(parsed.Hostname()); ok {
+			assessment.Category, assessment.Score = rule.category, rule.score
+			matched = true
+		}
+	}
+	if signals == nil {
+		return assessment
+	}
+
+	if !matched {
+		if category := signals.category(); category != Unknown {
+			assessment.Category, assessment.Score = category, DefaultScores[category]
+		}
+	}
+	assessment.Signals = signals.names()
+	if signals.Author {
+		assessment.Score += signalBonus
+	}
+	if signals.Published {
+		assessment.Score += signalBonus
+	}
+	assessment.Score = math.Min(assessment.Score, 1)
+	return assessment
+}
+
+// lookup finds the rule of the longest domain that the host is, or is a subdomain of.
+func (r *Reputation) lookup(host string) (rule, bool) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for host != "" {
+		if rule, ok := r.rules[host]; ok {
+			return rule, true
+		}
+		dot := strings.Index(host, ".")
+		if dot < 0 {
+			break
+		}
+		host = host[dot+1:]
+	}
+	return rule{}, false
+}
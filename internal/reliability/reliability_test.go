@@ -0,0 +1,81 @@
+package reliability
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDefaultReputation verifies that the shipped reputation list parses and classifies well-known domains.
+func TestDefaultReputation(t *testing.T) {
+	reputation := DefaultReputation()
+
+	tests := []struct {
+		url      string
+		category Category
+	}{
+		{"https://www.nature.com/articles/s41586-020-2649-2", PeerReviewed},
+		{"https://www.cdc.gov/flu/index.html", Government},
+		{"https://arxiv.org/abs/1706.03762", Preprint},
+		{"https://someone.substack.com/p/post", Blog},
+		{"https://en.wikipedia.org/wiki/Trust_(social_science)", UserGenerated},
+		{"https://example.org/papers/fukuyama", Unknown},
+	}
+
+	for _, tt := range tests {
+		assessment := reputation.Assess(tt.url, nil)
+		if assessment.Category != tt.category {
+			t.Errorf("Expected %s to be classified as %s, got %s", tt.url, tt.category, assessment.Category)
+		}
+	}
+}
+
+// TestParseReputation verifies that the longest matching domain wins and that scores can be overridden.
+func TestParseReputation(t *testing.T) {
+	reputation, err := ParseReputation(strings.NewReader(`
+		# Government sites, except one
+		gov              government
+		spin.example.gov news 0.3
+	`))
+	if err != nil {
+		t.Fatalf("Error parsing reputation: %v", err)
+	}
+
+	if assessment := reputation.Assess("https://data.census.gov/table", nil); assessment.Category != Government || assessment.Score != DefaultScores[Government] {
+		t.Errorf("Expected a government source with the default score, got %+v", assessment)
+	}
+	if assessment := reputation.Assess("https://www.spin.example.gov/", nil); assessment.Category != News || assessment.Score != 0.3 {
+		t.Errorf("Expected the more specific rule to win with a score of 0.3, got %+v", assessment)
+	}
+	if assessment := reputation.Assess("https://notgov.org/", nil); assessment.Category != Unknown {
+		t.Errorf("Expected a domain ending in the same letters not to match, got %+v", assessment)
+	}
+}
+
+// TestParseReputationErrors verifies that malformed lines are reported with their line number.
+func TestParseReputationErrors(t *testing.T) {
+	for _, list := range []string{
+		"example.com",
+		"example.com tabloid",
+		"example.com news 1.5",
+		"example.com unknown",
+	} {
+		if _, err := ParseReputation(strings.NewReader(list)); err == nil || !strings.Contains(err.Error(), "line 1") {
+			t.Errorf("Expected an error on line 1 for %q, got %v", list, err)
+		}
+	}
+}
+
+// TestAssessSignals verifies that page signals classify unknown domains and raise the score, but do not override the domain.
+func TestAssessSignals(t *testing.T) {
+	reputation := DefaultReputation()
+	signals := &Signals{ScholarlyMetadata: true, Author: true}
+
+	assessment := reputation.Assess("https://journal.example.org/article/1", signals)
+	if assessment.Category != PeerReviewed || assessment.Score != DefaultScores[PeerReviewed]+signalBonus {
+		t.Errorf("Expected a peer-reviewed source with an author bonus, got %+v", assessment)
+	}
+	assessment = reputation.Assess("https://medium.com/@someone/post", signals)
+	if assessment.Category != Blog {
+		t.Errorf("Expected the domain to take precedence over the page signals, got %+v", assessment)
+	}
+}
@@ -0,0 +1,114 @@
+package reliability
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Signals are the hints about a source found in the metadata of its page.
+type Signals struct {
+	ScholarlyMetadata bool   `json:"scholarly_metadata,omitempty"` // citation_* or DC identifiers used by journals and repositories
+	Author            bool   `json:"author,omitempty"`             // an author is named
+	Published         bool   `json:"published,omitempty"`          // a publication date is given
+	Article           bool   `json:"article,omitempty"`            // the page declares itself a news or article page
+	Generator         string `json:"generator,omitempty"`          // the software that generated the page, such as "WordPress 6.4"
+}
+
+// blogGenerators are the publishing platforms whose pages are treated as blogs when their domain is unknown.
+var blogGenerators = []string{"wordpress", "blogger", "ghost", "hugo", "jekyll", "medium", "substack", "tumblr", "wix", "squarespace"}
+
+// PageSignals reads the meta tags of a page's HTML.
+func PageSignals(page string) *Signals {
+	signals := &Signals{}
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return signals
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "meta" {
+			signals.readMeta(n)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return signals
+}
+
+// readMeta records the signal carried by a single meta tag, if any.
+func (s *Signals) readMeta(n *html.Node) {
+	var name, content string
+	for _, attr := range n.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "name", "property":
+			name = strings.ToLower(attr.Val)
+		case "content":
+			content = strings.TrimSpace(attr.Val)
+		}
+	}
+	if content == "" {
+		return
+	}
+
+	switch {
+	case name == "citation_doi" || name == "citation_journal_title" || name == "citation_pmid" || (name == "dc.identifier" && strings.Contains(strings.ToLower(content), "doi")):
+		s.ScholarlyMetadata = true
+	case name == "author" || name == "article:author" || name == "citation_author" || name == "dc.creator":
+		s.Author = true
+	case name == "article:published_time" || name == "citation_publication_date" || name == "citation_date" || name == "dc.date" || name == "date":
+		s.Published = true
+	case name == "og:type" && strings.EqualFold(content, "article"):
+		s.Article = true
+	case name == "generator":
+		s.Generator = content
+	}
+}
+
+// category infers the category of a page from its signals alone.
+func (s *Signals) category() Category {
+	generator := strings.ToLower(s.Generator)
+	switch {
+	case s.ScholarlyMetadata:
+		return PeerReviewed
+	case generator != "" && containsAny(generator, blogGenerators):
+		return Blog
+	case s.Article && s.Published:
+		return News
+	}
+	return Unknown
+}
+
+// names lists the signals that were found, for reporting.
+func (s *Signals) names() []string {
+	var names []string
+	if s.ScholarlyMetadata {
+		names = append(names, "scholarly_metadata")
+	}
+	if s.Author {
+		names = append(names, "author")
+	}
+	if s.Published {
+		names = append(names, "published_date")
+	}
+	if s.Article {
+		names = append(names, "article")
+	}
+	if s.Generator != "" {
+		names = append(names, "generator")
+	}
+	return names
+}
+
+// containsAny reports whether s contains any of the substrings.
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package reliability
+
+import (
+	"testing"
+)
+
+// TestPageSignals verifies that the meta tags of a page are read into signals and a category.
+func TestPageSignals(t *testing.T) {
+	tests := []struct {
+		page     string
+		category Category
+		names    int
+	}{
+		{`<html><head>
+			<meta name="citation_doi" content="10.1000/xyz123">
+			<meta name="citation_author" content="Doe, Jane">
+			<meta name="citation_publication_date" content="2019/05/01">
+		</head><body></body></html>`, PeerReviewed, 3},
+		{`<html><head>
+			<meta property="og:type" content="article">
+			<meta property="article:published_time" content="2021-03-04T10:00:00Z">
+		</head></html>`, News, 2},
+		{`<html><head><meta name="generator" content="WordPress 6.4.2"></head></html>`, Blog, 1},
+		{`<html><head><meta name="author" content=""></head></html>`, Unknown, 0},
+	}
+
+	for _, tt := range tests {
+		signals := PageSignals(tt.page)
+		if category := signals.category(); category != tt.category {
+			t.Errorf("Expected category %s for %s, got %s", tt.category, tt.page, category)
+		}
+		if names := signals.names(); len(names) != tt.names {
+			t.Errorf("Expected %d signals for %s, got %v", tt.names, tt.page, names)
+		}
+	}
+}
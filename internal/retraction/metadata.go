@@ -0,0 +1,49 @@
+package retraction
+
+import (
+	"citation-scanner/pkg/identifier"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// PageIdentifiers reads the DOI, PubMed ID and title a publisher declares in the meta tags of a page.
+func PageIdentifiers(page string) Identifiers {
+	var ids Identifiers
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return ids
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "meta" {
+			var name, content string
+			for _, attr := range n.Attr {
+				switch strings.ToLower(attr.Key) {
+				case "name", "property":
+					name = strings.ToLower(attr.Val)
+				case "content":
+					content = strings.TrimSpace(attr.Val)
+				}
+			}
+			switch name {
+			case "citation_doi", "dc.identifier", "prism.doi":
+				if doi := identifier.FindDOI(content); doi != "" && ids.DOI == "" {
+					ids.DOI = doi
+				}
+			case "citation_pmid":
+				ids.PMID = content
+			case "citation_title", "dc.title":
+				if ids.Title == "" {
+					ids.Title = content
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return ids
+}
@@ -0,0 +1,18 @@
+package retraction
+
+import (
+	"testing"
+)
+
+// TestPageIdentifiers verifies that the identifiers declared in a publisher's meta tags are read.
+func TestPageIdentifiers(t *testing.T) {
+	ids := PageIdentifiers(`<html><head>
+		<meta name="citation_title" content="Trust and the Wealth of Nations Revisited">
+		<meta name="dc.identifier" content="doi:10.5555/JSC.1995.0042">
+		<meta name="citation_pmid" content="12345678">
+	</head><body></body></html>`)
+
+	if ids.DOI != "10.5555/jsc.1995.0042" || ids.PMID != "12345678" || ids.Title != "Trust and the Wealth of Nations Revisited" {
+		t.Errorf("Unexpected identifiers %+v", ids)
+	}
+}
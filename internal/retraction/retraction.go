@@ -0,0 +1,159 @@
+package retraction
+
+import (
+	"citation-scanner/pkg/identifier"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Record is a retracted publication from the retraction database.
+type Record struct {
+	DOI            string `json:"doi,omitempty"`
+	PMID           string `json:"pmid,omitempty"`
+	Title          string `json:"title,omitempty"`
+	Journal        string `json:"journal,omitempty"`
+	RetractionDate string `json:"retraction_date,omitempty"`
+	Reason         string `json:"reason,omitempty"`
+}
+
+// Identifiers are the ways a source can be matched against the database.
+type Identifiers struct {
+	DOI   string `json:"doi,omitempty"`
+	PMID  string `json:"pmid,omitempty"`
+	Title string `json:"title,omitempty"`
+}
+
+// Database indexes retracted publications by DOI, PubMed ID and title.
+type Database struct {
+	byDOI   map[string]*Record
+	byPMID  map[string]*Record
+	byTitle map[string]*Record
+}
+
+// columns maps the accepted CSV headers, lowercased and without spaces or underscores, to the fields of a Record.
+// Both plain names and the column names of the Retraction Watch export are recognized.
+var columns = map[string]string{
+	"doi": "doi", "originalpaperdoi": "doi",
+	"pmid": "pmid", "pubmedid": "pmid", "originalpaperpubmedid": "pmid",
+	"title":          "title",
+	"journal":        "journal",
+	"retractiondate": "date", "date": "date",
+	"reason": "reason",
+}
+
+// Load imports a retraction database from a CSV file.
+func Load(path string) (*Database, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open retraction database: %v", err)
+	}
+	defer file.Close()
+	return Import(file)
+}
+
+// Import reads a CSV export of retracted publications. The header row names the columns; at least
+// one of the DOI, PubMed ID or title columns is required and unknown columns are ignored.
+func Import(r io.Reader) (*Database, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retraction database header: %v", err)
+	}
+	fields := make(map[string]int)
+	for i, name := range header {
+		key := strings.NewReplacer(" ", "", "_", "", "\ufeff", "").Replace(strings.ToLower(name))
+		if field, ok := columns[key]; ok {
+			if _, seen := fields[field]; !seen {
+				fields[field] = i
+			}
+		}
+	}
+	if _, ok := fields["doi"]; !ok {
+		if _, ok := fields["pmid"]; !ok {
+			if _, ok := fields["title"]; !ok {
+				return nil, fmt.Errorf("retraction database has no DOI, PubMed ID or title column")
+			}
+		}
+	}
+
+	db := &Database{byDOI: make(map[string]*Record), byPMID: make(map[string]*Record), byTitle: make(map[string]*Record)}
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read retraction database line %d: %v", line, err)
+		}
+		value := func(field string) string {
+			if i, ok := fields[field]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		record := &Record{
+			DOI:            identifier.NormalizeDOI(value("doi")),
+			PMID:           identifier.NormalizePMID(value("pmid")),
+			Title:          value("title"),
+			Journal:        value("journal"),
+			RetractionDate: value("date"),
+			Reason:         value("reason"),
+		}
+		if record.DOI != "" {
+			db.byDOI[record.DOI] = record
+		}
+		if record.PMID != "" {
+			db.byPMID[record.PMID] = record
+		}
+		if title := normalizeTitle(record.Title); title != "" {
+			db.byTitle[title] = record
+		}
+	}
+	return db, nil
+}
+
+// Len returns the number of publications in the database that can be matched.
+func (db *Database) Len() int {
+	seen := make(map[*Record]bool)
+	for _, index := range []map[string]*Record{db.byDOI, db.byPMID, db.byTitle} {
+		for _, record := range index {
+			seen[record] = true
+		}
+	}
+	return len(seen)
+}
+
+// Lookup finds a retracted publication by DOI, then by PubMed ID, then by title.
+func (db *Database) Lookup(ids Identifiers) (*Record, bool) {
+	if record, ok := db.byDOI[identifier.NormalizeDOI(ids.DOI)]; ok && ids.DOI != "" {
+		return record, true
+	}
+	if record, ok := db.byPMID[identifier.NormalizePMID(ids.PMID)]; ok && ids.PMID != "" {
+		return record, true
+	}
+	if title := normalizeTitle(ids.Title); title != "" {
+		if record, ok := db.byTitle[title]; ok {
+			return record, true
+		}
+	}
+	return nil, false
+}
+
+// normalizeTitle lowercases a title and reduces it to its words, so that punctuation and spacing differences still match.
+func normalizeTitle(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) < 3 {
+		// Titles this short match too many unrelated pages
+		return ""
+	}
+	return strings.Join(words, " ")
+}
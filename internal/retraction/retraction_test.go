@@ -0,0 +1,53 @@
+package retraction
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLoad verifies that a Retraction Watch style export is imported and can be searched by DOI, PubMed ID and title.
+func TestLoad(t *testing.T) {
+	db, err := Load("testdata/retractions.csv")
+	if err != nil {
+		t.Fatalf("Error loading retraction database: %v", err)
+	}
+	if db.Len() != 3 {
+		t.Errorf("Expected 3 retracted publications, got %d", db.Len())
+	}
+
+	tests := []struct {
+		ids  Identifiers
+		want string
+	}{
+		{Identifiers{DOI: "https://doi.org/10.1016/s0140-6736(97)11096-0"}, "9500320"},
+		{Identifiers{DOI: "10.1016/S0140-6736(20)31180-6."}, "32450107"},
+		{Identifiers{PMID: "9500320"}, "9500320"},
+		{Identifiers{Title: "Trust and the wealth of nations — revisited"}, ""},
+	}
+	for _, tt := range tests {
+		record, ok := db.Lookup(tt.ids)
+		if !ok {
+			t.Errorf("Expected %+v to be found", tt.ids)
+			continue
+		}
+		if record.PMID != tt.want {
+			t.Errorf("Expected %+v to match PubMed ID %q, got %q", tt.ids, tt.want, record.PMID)
+		}
+	}
+
+	for _, ids := range []Identifiers{{DOI: "10.1038/nature12373"}, {PMID: "0"}, {Title: "Trust"}, {}} {
+		if record, ok := db.Lookup(ids); ok {
+			t.Errorf("Expected %+v not to be found, got %+v", ids, record)
+		}
+	}
+}
+
+// TestImportErrors verifies that exports without any identifying column are rejected.
+func TestImportErrors(t *testing.T) {
+	if _, err := Import(strings.NewReader("Journal,Reason\nThe Lancet,Fraud\n")); err == nil {
+		t.Error("Expected an error for a database without identifiers, got nil")
+	}
+	if _, err := Import(strings.NewReader("")); err == nil {
+		t.Error("Expected an error for an empty database, got nil")
+	}
+}
@@ -0,0 +1,153 @@
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the Wayback Machine, whose availability API the resolver queries by default.
+const DefaultBaseURL = "https://archive.org"
+
+// timestampLayout is the format of Wayback timestamps.
+const timestampLayout = "20060102150405"
+
+// snapshotPath matches the timestamp of a Wayback snapshot URL, such as "/web/20200101000000/".
+var snapshotPath = regexp.MustCompile(`/web/(\d{14})/`)
+
+// Snapshot is an archived copy of a page.
+type Snapshot struct {
+	URL         string    `json:"url"`
+	OriginalURL string    `json:"original_url"`
+	Timestamp   time.Time `json:"timestamp"`
+	Status      int       `json:"status,omitempty"`
+}
+
+// RawURL returns the URL of the snapshot as it was captured, without the banner and rewritten links
+// the archive adds to the pages it serves, when the snapshot URL has the Wayback form.
+func (s *Snapshot) RawURL() string {
+	return snapshotPath.ReplaceAllString(s.URL, "/web/${1}id_/")
+}
+
+// Resolver finds archived copies of pages through a Wayback-compatible availability API.
+type Resolver struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewResolver creates and returns a new Resolver with default settings.
+func NewResolver(opts ...func(*Resolver)) *Resolver {
+	resolver := &Resolver{
+		baseURL: DefaultBaseURL,
+		client:  &http.Client{Timeout: 20 * time.Second}, // Default timeout per lookup
+	}
+
+	// Apply options to override defaults if provided
+	for _, opt := range opts {
+		opt(resolver)
+	}
+	return resolver
+}
+
+// WithBaseURL is an option to query another Wayback-compatible archive, or a local stand-in, instead of the Wayback Machine.
+func WithBaseURL(baseURL string) func(*Resolver) {
+	return func(r *Resolver) {
+		r.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient is an option to send the lookups with a custom HTTP client.
+func WithHTTPClient(client *http.Client) func(*Resolver) {
+	return func(r *Resolver) {
+		r.client = client
+	}
+}
+
+// availability is the response of the availability API.
+type availability struct {
+	ArchivedSnapshots struct {
+		Closest *struct {
+			Available bool   `json:"available"`
+			URL       string `json:"url"`
+			Timestamp string `json:"timestamp"`
+			Status    string `json:"status"`
+		} `json:"closest"`
+	} `json:"archived_snapshots"`
+}
+
+// Closest returns the snapshot of a page closest to the given time, or the most recent one when the time is zero.
+// It returns nil without an error when the page was never archived.
+func (r *Resolver) Closest(pageURL string, at time.Time) (*Snapshot, error) {
+	query := url.Values{"url": {pageURL}}
+	if !at.IsZero() {
+		query.Set("timestamp", at.UTC().Format(timestampLayout))
+	}
+
+	resp, err := r.client.Get(r.baseURL + "/wayback/available?" + query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the archive: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status from the archive: %s", resp.Status)
+	}
+
+	var response availability
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to parse the archive response: %v", err)
+	}
+	closest := response.ArchivedSnapshots.Closest
+	if closest == nil || !closest.Available || closest.URL == "" {
+		return nil, nil
+	}
+
+	snapshot := &Snapshot{URL: closest.URL, OriginalURL: pageURL}
+	if timestamp, err := time.Parse(timestampLayout, closest.Timestamp); err == nil {
+		snapshot.Timestamp = timestamp
+	}
+	if status, err := strconv.Atoi(closest.Status); err == nil {
+		snapshot.Status = status
+	}
+	return snapshot, nil
+}
+
+// archiveHosts are the web archives whose links are recognized as snapshots. The rest of archive.org hosts
+// digitized books and media rather than snapshots, so its links only count on the Wayback hosts or with a
+// Wayback snapshot path.
+var archiveHosts = []string{"web.archive.org", "wayback.archive.org", "archive.today", "archive.ph", "archive.is", "archive.li", "webcitation.org", "arquivo.pt", "webarchive.org.uk", "webarchive.loc.gov"}
+
+// snapshotURL matches a Wayback snapshot URL, capturing its timestamp and the original URL.
+var snapshotURL = regexp.MustCompile(`/web/(\d{14})(?:[a-z]{2}_)?/(.+)$`)
+
+// IsArchiveURL reports whether a URL points at a web archive rather than at the live page.
+func IsArchiveURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, archiveHost := range archiveHosts {
+		if host == archiveHost || strings.HasSuffix(host, "."+archiveHost) {
+			return true
+		}
+	}
+	return snapshotURL.MatchString(parsed.Path)
+}
+
+// SnapshotFromURL describes the snapshot an archive URL points at. The timestamp and original URL
+// are only known for Wayback-style URLs such as "https://web.archive.org/web/20200101000000/https://example.com/".
+func SnapshotFromURL(archiveURL string) *Snapshot {
+	snapshot := &Snapshot{URL: archiveURL}
+	if match := snapshotURL.FindStringSubmatch(archiveURL); match != nil {
+		if timestamp, err := time.Parse(timestampLayout, match[1]); err == nil {
+			snapshot.Timestamp = timestamp
+		}
+		snapshot.OriginalURL = match[2]
+	}
+	return snapshot
+}
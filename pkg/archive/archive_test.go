@@ -0,0 +1,87 @@
+package archive
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestArchive serves a stand-in availability API that knows a single page.
+func newTestArchive(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wayback/available" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("url") != "https://example.org/gone" {
+			fmt.Fprint(w, `{"url": "https://example.org/never", "archived_snapshots": {}}`)
+			return
+		}
+		timestamp := "20190510120000"
+		if r.URL.Query().Get("timestamp") != "" {
+			timestamp = "20150101000000"
+		}
+		fmt.Fprintf(w, `{"archived_snapshots": {"closest": {"status": "200", "available": true, "url": "http://%s/web/%s/https://example.org/gone", "timestamp": "%s"}}}`, r.Host, timestamp, timestamp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestClosest verifies that snapshots are found through the configured base URL and missing pages return nil.
+func TestClosest(t *testing.T) {
+	server := newTestArchive(t)
+	resolver := NewResolver(WithBaseURL(server.URL + "/"))
+
+	snapshot, err := resolver.Closest("https://example.org/gone", time.Time{})
+	if err != nil {
+		t.Fatalf("Error resolving snapshot: %v", err)
+	}
+	if snapshot == nil || snapshot.Timestamp.Year() != 2019 || snapshot.Status != http.StatusOK || snapshot.OriginalURL != "https://example.org/gone" {
+		t.Fatalf("Unexpected snapshot %+v", snapshot)
+	}
+	if want := server.URL + "/web/20190510120000id_/https://example.org/gone"; snapshot.RawURL() != want {
+		t.Errorf("Expected raw URL %s, got %s", want, snapshot.RawURL())
+	}
+
+	snapshot, err = resolver.Closest("https://example.org/gone", time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || snapshot == nil || snapshot.Timestamp.Year() != 2015 {
+		t.Errorf("Expected the snapshot closest to 2015, got %+v, %v", snapshot, err)
+	}
+
+	snapshot, err = resolver.Closest("https://example.org/never", time.Time{})
+	if err != nil || snapshot != nil {
+		t.Errorf("Expected no snapshot and no error for a page that was never archived, got %+v, %v", snapshot, err)
+	}
+}
+
+// TestClosestError verifies that archive failures are reported as errors.
+func TestClosestError(t *testing.T) {
+	server := newTestArchive(t)
+	if _, err := NewResolver(WithBaseURL(server.URL+"/missing")).Closest("https://example.org/gone", time.Time{}); err == nil {
+		t.Error("Expected an error for an archive that does not answer, got nil")
+	}
+}
+
+// TestSnapshotFromURL verifies that archive links are recognized and their timestamp and original URL read.
+func TestSnapshotFromURL(t *testing.T) {
+	link := "https://web.archive.org/web/20190510120000/https://example.com/article?id=1"
+	if !IsArchiveURL(link) || !IsArchiveURL("https://archive.ph/AbCdE") || IsArchiveURL("https://example.com/web/archive") {
+		t.Error("Unexpected archive link recognition")
+	}
+	if IsArchiveURL("https://archive.org/details/trustsocialvirtu00fuku") || !IsArchiveURL("https://archive.org/web/20190510120000/https://example.com/") {
+		t.Error("Expected only Wayback snapshots of the Internet Archive to be recognized")
+	}
+
+	snapshot := SnapshotFromURL(link)
+	if snapshot.OriginalURL != "https://example.com/article?id=1" || snapshot.Timestamp.Year() != 2019 {
+		t.Errorf("Unexpected snapshot %+v", snapshot)
+	}
+	if snapshot.RawURL() != "https://web.archive.org/web/20190510120000id_/https://example.com/article?id=1" {
+		t.Errorf("Unexpected raw URL %s", snapshot.RawURL())
+	}
+	if snapshot := SnapshotFromURL("https://archive.ph/AbCdE"); snapshot.OriginalURL != "" || !snapshot.Timestamp.IsZero() {
+		t.Errorf("Expected nothing to be known of a non-Wayback snapshot, got %+v", snapshot)
+	}
+}
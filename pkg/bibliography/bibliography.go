@@ -0,0 +1,390 @@
+package bibliography
+
+import (
+	"citation-scanner/pkg/identifier"
+	"citation-scanner/pkg/webscraper"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSL item types produced by the parser.
+const (
+	TypeArticleJournal   = "article-journal"
+	TypeArticleNewspaper = "article-newspaper"
+	TypeBook             = "book"
+	TypeWebpage          = "webpage"
+	TypeDocument         = "document"
+)
+
+// Item is a bibliographic record in CSL-JSON, the format read by citation processors and reference managers.
+type Item struct {
+	ID             string `json:"id"`
+	Type           string `json:"type"`
+	Title          string `json:"title,omitempty"`
+	Author         []Name `json:"author,omitempty"`
+	ContainerTitle string `json:"container-title,omitempty"`
+	Publisher      string `json:"publisher,omitempty"`
+	PublisherPlace string `json:"publisher-place,omitempty"`
+	Issued         *Date  `json:"issued,omitempty"`
+	Accessed       *Date  `json:"accessed,omitempty"`
+	Volume         string `json:"volume,omitempty"`
+	Issue          string `json:"issue,omitempty"`
+	Page           string `json:"page,omitempty"`
+	DOI            string `json:"DOI,omitempty"`
+	ISBN           string `json:"ISBN,omitempty"`
+	PMID           string `json:"PMID,omitempty"`
+	URL            string `json:"URL,omitempty"`
+}
+
+// Name is a CSL name. Names that cannot be split into family and given names are kept whole as a literal.
+type Name struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+// Date is a CSL date, holding the year, month and day that are known.
+type Date struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// NewDate returns the CSL date of the day of t.
+func NewDate(t time.Time) *Date {
+	return dateOf(t, 3)
+}
+
+// Year returns the year of the date, or 0 if it has none.
+func (d *Date) Year() int {
+	if d == nil || len(d.DateParts) == 0 || len(d.DateParts[0]) == 0 {
+		return 0
+	}
+	return d.DateParts[0][0]
+}
+
+var (
+	// retrievedNote and archivedNote match the notes citation templates append about access and archiving.
+	retrievedNote = regexp.MustCompile(`(?i)(?:retrieved|accessed)(?: on)?:?\s+[^.]+\.?`)
+	archivedNote  = regexp.MustCompile(`(?i)(?:archived|archived from the original)(?: from the original)?(?: on)?\s+[^.]*\.?`)
+
+	// authorDate matches "Authors (date). Rest", the opening of most citation styles.
+	authorDate = regexp.MustCompile(`^(.*?)\s*\(([^()]*\b\d{4}[a-z]?[^()]*)\)[.,:]?\s*(.*)$`)
+	yearOnly   = regexp.MustCompile(`\b(1[5-9]\d{2}|20\d{2})\b`)
+
+	quotedTitle      = regexp.MustCompile(`^["“”']([^"“”]+?)[,.]?["“”'][.,]?\s*(.*)$`)
+	volumeIssue      = regexp.MustCompile(`^(\d+)\s*(?:\(([\w–-]+)\))?\s*[:,]\s*(?:pp?\.\s*)?(\d+(?:\s*[–-]\s*\d+)?)$`)
+	pagesOnly        = regexp.MustCompile(`(?i)^pp?\.\s*(\d+(?:\s*[–-]\s*\d+)?)$`)
+	volumeLabel      = regexp.MustCompile(`(?i)^vol(?:ume)?\.?\s*(\d+)(?:,?\s*(?:no|issue)\.?\s*(\d+))?$`)
+	placePublisher   = regexp.MustCompile(`^([A-Z][\w .,'-]{1,40}?):\s*([A-Z].+)$`)
+	publisherWord    = regexp.MustCompile(`\b(Press|Publishing|Publishers|Publications|Books|Verlag|Editions|Éditions|Wiley|Springer|Elsevier|Routledge|Penguin|HarperCollins|Macmillan)\b`)
+	organizationWord = regexp.MustCompile(`\b(Organization|Organisation|Institute|Association|Society|Agency|Department|Ministry|Bureau|Council|Foundation|Commission|Office|Bank|Centre|Center|University)\b`)
+	newspaperWord    = regexp.MustCompile(`\b(Times|News|Post|Guardian|Herald|Tribune|Telegraph|Gazette|Chronicle|Daily|Independent|Observer|Reuters|Associated Press|BBC)\b`)
+	initials         = regexp.MustCompile(`^(?:[A-Z]\.\s*-?)+$`)
+
+	// nameSeparator matches the "and" or "&" between the last two authors, with the comma before it if any.
+	nameSeparator = regexp.MustCompile(`\s*(?:,\s*)?(?:&|\band\b)\s*`)
+)
+
+// FromReference parses a reference list entry into a CSL item, using the links, access date and
+// italics the scraper found in its markup.
+func FromReference(reference webscraper.Reference) Item {
+	item := Parse(reference.Text, reference.Italics)
+	item.ID = reference.ID
+	if item.ID == "" {
+		item.ID = fmt.Sprintf("ref-%d", reference.Index)
+	}
+	if reference.URL != "" {
+		item.URL = reference.URL
+		item.identifyURL()
+	}
+	if !reference.AccessDate.IsZero() {
+		item.Accessed = NewDate(reference.AccessDate)
+	}
+	// The link and its identifiers can tell more about the kind of work
+	item.Type = itemType(item)
+	return item
+}
+
+// Parse reads the authors, date, title, container, publisher, volume, issue, pages and identifiers of a
+// formatted reference, such as one rendered by Wikipedia's citation templates or written in APA style.
+// The italicized parts of the reference, when known, help tell titles and journals apart.
+func Parse(text string, italics []string) Item {
+	item := Item{}
+	text = strings.Join(strings.Fields(text), " ")
+
+	// Take out the links, identifiers and notes first, so that they are not read as titles or publishers
+	rest := text
+	if match := webscraper.BareURL.FindString(rest); match != "" {
+		item.URL = match
+		rest = strings.Replace(rest, match, "", -1)
+	}
+	ids, rest := identifier.Extract(rest)
+	for _, id := range ids {
+		switch id.Kind {
+		case identifier.DOI:
+			item.DOI = id.Value
+		case identifier.ISBN:
+			item.ISBN = id.Value
+		case identifier.PMID:
+			item.PMID = id.Value
+		}
+	}
+	item.identifyURL()
+	rest = retrievedNote.ReplaceAllString(rest, "")
+	rest = archivedNote.ReplaceAllString(rest, "")
+
+	// Authors and date
+	if match := authorDate.FindStringSubmatch(rest); match != nil {
+		item.Author = parseNames(match[1])
+		item.Issued = parseDate(match[2])
+		rest = match[3]
+	}
+
+	// Title, quoted in most styles for articles and in italics for books
+	rest = strings.TrimSpace(rest)
+	if match := quotedTitle.FindStringSubmatch(rest); match != nil {
+		item.Title, rest = strings.TrimSpace(match[1]), match[2]
+	} else if len(italics) > 0 && strings.HasPrefix(rest, italics[0]) {
+		item.Title, rest = italics[0], strings.TrimPrefix(rest, italics[0])
+		italics = italics[1:]
+	} else {
+		item.Title, rest = splitSentence(rest)
+	}
+
+	// The remaining segments are the container, publisher, volume, issue and pages
+	for _, segment := range segments(rest) {
+		switch match := volumeIssue.FindStringSubmatch(segment); {
+		case match != nil:
+			item.Volume, item.Issue, item.Page = match[1], match[2], normalizePages(match[3])
+			continue
+		}
+		if match := pagesOnly.FindStringSubmatch(segment); match != nil {
+			item.Page = normalizePages(match[1])
+			continue
+		}
+		if match := volumeLabel.FindStringSubmatch(segment); match != nil {
+			item.Volume, item.Issue = match[1], match[2]
+			continue
+		}
+		if item.Issued == nil {
+			if match := yearOnly.FindString(segment); match != "" && strings.TrimSpace(strings.Trim(segment, "()")) == match {
+				item.Issued = parseDate(match)
+				continue
+			}
+		}
+		if match := placePublisher.FindStringSubmatch(segment); match != nil && item.Publisher == "" {
+			item.PublisherPlace, item.Publisher = strings.TrimSpace(match[1]), strings.TrimSpace(match[2])
+			continue
+		}
+		if publisherWord.MatchString(segment) && item.Publisher == "" && !contains(italics, segment) {
+			item.Publisher = segment
+			continue
+		}
+		if item.ContainerTitle == "" {
+			item.ContainerTitle = segment
+		}
+	}
+	if item.Issued == nil {
+		if match := yearOnly.FindString(text); match != "" {
+			item.Issued = parseDate(match)
+		}
+	}
+
+	item.Type = itemType(item)
+	return item
+}
+
+// identifyURL fills in the DOI or PubMed ID of an item whose URL is a resolver link or landing page, when the text had none.
+func (item *Item) identifyURL() {
+	id, ok := identifier.FromURL(item.URL)
+	switch {
+	case !ok:
+	case id.Kind == identifier.DOI && item.DOI == "":
+		item.DOI = id.Value
+	case id.Kind == identifier.PMID && item.PMID == "":
+		item.PMID = id.Value
+	}
+}
+
+// itemType guesses the CSL type of an item from the fields that were found.
+func itemType(item Item) string {
+	switch {
+	case item.ContainerTitle != "" && newspaperWord.MatchString(item.ContainerTitle):
+		return TypeArticleNewspaper
+	case item.DOI != "" || item.Volume != "" || item.PMID != "" || item.ContainerTitle != "" && item.Page != "":
+		return TypeArticleJournal
+	case item.ISBN != "" || item.Publisher != "":
+		return TypeBook
+	case item.URL != "":
+		return TypeWebpage
+	}
+	return TypeDocument
+}
+
+// parseNames splits an author list such as "Doe, Jane; Roe, Richard", "Putnam, R. D., & Leonardi, R." or "Jane Doe and Richard Roe".
+func parseNames(authors string) []Name {
+	authors = strings.TrimSpace(authors)
+	for _, suffix := range []string{"et al.", "et al"} {
+		authors = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(authors), suffix))
+	}
+	authors = strings.TrimSuffix(authors, ",")
+	for _, suffix := range []string{"(ed.)", "(eds.)", "(ed)", "(eds)"} {
+		authors = strings.TrimSpace(strings.TrimSuffix(authors, suffix))
+	}
+	if authors == "" {
+		return nil
+	}
+
+	var names []Name
+	if strings.Contains(authors, ";") {
+		for _, author := range strings.Split(authors, ";") {
+			if name, ok := parseName(author); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+
+	parts := nameSeparator.Split(authors, -1)
+	var tokens []string
+	for _, part := range parts {
+		for _, token := range strings.Split(part, ",") {
+			if token = strings.TrimSpace(token); token != "" {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+
+	// "Family, Given, Family, Given" when every other token is a given name or initials
+	if len(tokens)%2 == 0 && pairedNames(tokens) {
+		for i := 0; i < len(tokens); i += 2 {
+			names = append(names, Name{Family: tokens[i], Given: tokens[i+1]})
+		}
+		return names
+	}
+	for _, token := range tokens {
+		if name, ok := parseName(token); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// pairedNames reports whether tokens alternate between family names and given names or initials.
+func pairedNames(tokens []string) bool {
+	for i := 1; i < len(tokens); i += 2 {
+		if strings.Contains(tokens[i-1], " ") && !initials.MatchString(tokens[i]) {
+			return false
+		}
+		if len(strings.Fields(tokens[i])) > 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// parseName reads a single name written as "Family, Given" or "Given Family". Organizations and
+// single words are kept as literals.
+func parseName(author string) (Name, bool) {
+	author = strings.TrimSpace(author)
+	switch {
+	case author == "":
+		return Name{}, false
+	case strings.Contains(author, ","):
+		parts := strings.SplitN(author, ",", 2)
+		return Name{Family: strings.TrimSpace(parts[0]), Given: strings.TrimSpace(parts[1])}, true
+	}
+	fields := strings.Fields(author)
+	if len(fields) < 2 || len(fields) > 4 || publisherWord.MatchString(author) || organizationWord.MatchString(author) {
+		return Name{Literal: author}, true
+	}
+	return Name{Family: fields[len(fields)-1], Given: strings.Join(fields[:len(fields)-1], " ")}, true
+}
+
+// dateLayouts are the date formats of references, with the number of date parts each gives.
+var dateLayouts = []struct {
+	layout string
+	parts  int
+}{
+	{"2006", 1},
+	{"January 2006", 2},
+	{"Jan 2006", 2},
+	{"2006-01", 2},
+	{"2 January 2006", 3},
+	{"January 2, 2006", 3},
+	{"2 Jan 2006", 3},
+	{"Jan 2, 2006", 3},
+	{"2006-01-02", 3},
+	{"2006/01/02", 3},
+	{"2006, January 2", 3},
+}
+
+// parseDate reads a reference date such as "1995", "1995a", "March 2020" or "12 March 2020", keeping the year
+// alone when the rest of the date cannot be read.
+func parseDate(text string) *Date {
+	text = strings.TrimSpace(text)
+	if len(text) == 5 && text[4] >= 'a' && text[4] <= 'z' {
+		text = text[:4]
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout.layout, text); err == nil {
+			return dateOf(t, layout.parts)
+		}
+	}
+	if match := yearOnly.FindString(text); match != "" {
+		year, _ := strconv.Atoi(match)
+		return &Date{DateParts: [][]int{{year}}}
+	}
+	return nil
+}
+
+// dateOf converts a time to a CSL date with the given number of parts.
+func dateOf(t time.Time, parts int) *Date {
+	date := []int{t.Year(), int(t.Month()), t.Day()}
+	return &Date{DateParts: [][]int{date[:parts]}}
+}
+
+// splitSentence splits the first sentence off a text, not counting the periods of initials or abbreviations.
+func splitSentence(text string) (string, string) {
+	for i := 0; i < len(text); i++ {
+		if text[i] != '.' || i+1 < len(text) && text[i+1] != ' ' {
+			continue
+		}
+		word := text[strings.LastIndex(text[:i], " ")+1 : i]
+		if len(word) <= 1 {
+			continue
+		}
+		return strings.TrimSpace(text[:i]), text[i+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(text, ".")), ""
+}
+
+// segments splits the rest of a reference into its period-separated segments.
+func segments(text string) []string {
+	var result []string
+	for text = strings.Trim(text, " .,;"); text != ""; text = strings.Trim(text, " .,;") {
+		var segment string
+		segment, text = splitSentence(text)
+		if segment = strings.Trim(segment, " ,;"); segment != "" {
+			result = append(result, segment)
+		}
+	}
+	return result
+}
+
+// normalizePages writes page ranges with an en dash, as CSL expects.
+func normalizePages(pages string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(strings.ReplaceAll(pages, "–", "-"), "-", " – ")), "")
+}
+
+// contains reports whether a string is in a list.
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package bibliography
+
+import (
+	"citation-scanner/pkg/webscraper"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestParse verifies that the fields of references in common citation styles are read into CSL items.
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		italics []string
+		want    Item
+	}{
+		{
+			name:    "journal article",
+			text:    `Doe, Jane; Roe, Richard (2019). "Trust and growth". Journal of Economics. 12 (3): 45–67. doi:10.1000/xyz123. PMID 12345678.`,
+			italics: []string{"Journal of Economics"},
+			want: Item{
+				Type:           TypeArticleJournal,
+				Title:          "Trust and growth",
+				Author:         []Name{{Family: "Doe", Given: "Jane"}, {Family: "Roe", Given: "Richard"}},
+				ContainerTitle: "Journal of Economics",
+				Issued:         &Date{DateParts: [][]int{{2019}}},
+				Volume:         "12",
+				Issue:          "3",
+				Page:           "45–67",
+				DOI:            "10.1000/xyz123",
+				PMID:           "12345678",
+			},
+		},
+		{
+			name:    "book",
+			text:    `Fukuyama, Francis (1995). Trust: The Social Virtues and the Creation of Prosperity. New York: Free Press. ISBN 978-0-02-910976-2.`,
+			italics: []string{"Trust: The Social Virtues and the Creation of Prosperity"},
+			want: Item{
+				Type:           TypeBook,
+				Title:          "Trust: The Social Virtues and the Creation of Prosperity",
+				Author:         []Name{{Family: "Fukuyama", Given: "Francis"}},
+				Publisher:      "Free Press",
+				PublisherPlace: "New York",
+				Issued:         &Date{DateParts: [][]int{{1995}}},
+				ISBN:           "9780029109762",
+			},
+		},
+		{
+			name: "APA book",
+			text: `Putnam, R. D., & Leonardi, R. (1993). Making democracy work. Princeton University Press.`,
+			want: Item{
+				Type:      TypeBook,
+				Title:     "Making democracy work",
+				Author:    []Name{{Family: "Putnam", Given: "R. D."}, {Family: "Leonardi", Given: "R."}},
+				Publisher: "Princeton University Press",
+				Issued:    &Date{DateParts: [][]int{{1993}}},
+			},
+		},
+		{
+			name:    "news article",
+			text:    `Smith, John (12 March 2020). "Markets fall". The Guardian. Retrieved 13 March 2020.`,
+			italics: []string{"The Guardian"},
+			want: Item{
+				Type:           TypeArticleNewspaper,
+				Title:          "Markets fall",
+				Author:         []Name{{Family: "Smith", Given: "John"}},
+				ContainerTitle: "The Guardian",
+				Issued:         &Date{DateParts: [][]int{{2020, 3, 12}}},
+			},
+		},
+		{
+			name: "web page",
+			text: `"World Values Survey 2020". https://example.net/survey`,
+			want: Item{
+				Type:   TypeWebpage,
+				Title:  "World Values Survey 2020",
+				Issued: &Date{DateParts: [][]int{{2020}}},
+				URL:    "https://example.net/survey",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Parse(test.text, test.italics); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Parse(%q)\n got %+v\nwant %+v", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+// TestParseNames verifies that author lists in different styles are split into family and given names.
+func TestParseNames(t *testing.T) {
+	tests := map[string][]Name{
+		"Jane Doe and Richard Roe":  {{Family: "Doe", Given: "Jane"}, {Family: "Roe", Given: "Richard"}},
+		"Doe, J.; Roe, R.":          {{Family: "Doe", Given: "J."}, {Family: "Roe", Given: "R."}},
+		"World Health Organization": {{Literal: "World Health Organization"}},
+		"Doe, Jane, et al.":         {{Family: "Doe", Given: "Jane"}},
+		"":                          nil,
+	}
+	for authors, want := range tests {
+		if got := parseNames(authors); !reflect.DeepEqual(got, want) {
+			t.Errorf("parseNames(%q) = %+v, want %+v", authors, got, want)
+		}
+	}
+}
+
+// TestFromReference verifies that the ID, link and access date of a scraped reference are kept on its item.
+func TestFromReference(t *testing.T) {
+	item := FromReference(webscraper.Reference{
+		Index:      2,
+		Text:       `"Live page".`,
+		URL:        "https://example.org/live",
+		AccessDate: time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC),
+	})
+	want := Item{
+		ID:       "ref-2",
+		Type:     TypeWebpage,
+		Title:    "Live page",
+		URL:      "https://example.org/live",
+		Accessed: &Date{DateParts: [][]int{{2018, 1, 2}}},
+	}
+	if !reflect.DeepEqual(item, want) {
+		t.Errorf("Expected %+v, got %+v", want, item)
+	}
+}
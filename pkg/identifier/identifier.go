@@ -0,0 +1,238 @@
+package identifier
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Kind is the scheme of a scholarly identifier.
+type Kind string
+
+const (
+	DOI   Kind = "doi"
+	PMID  Kind = "pmid"
+	ArXiv Kind = "arxiv"
+	ISBN  Kind = "isbn"
+)
+
+// Identifier is a normalized DOI, PubMed ID, arXiv ID or ISBN.
+type Identifier struct {
+	Kind  Kind   `json:"kind"`
+	Value string `json:"value"`
+}
+
+// String writes the identifier with its scheme, such as "doi:10.1038/nature12373" or "isbn:9780029109768".
+func (id Identifier) String() string {
+	return string(id.Kind) + ":" + id.Value
+}
+
+var (
+	// doiPattern matches a DOI anywhere in a string, such as a doi.org link or a publisher URL.
+	doiPattern = regexp.MustCompile(`\b10\.\d{4,9}/[^\s"'<>?#]+`)
+
+	// The labelled patterns match identifiers written out in text, such as "PMID 12345678" or "ISBN 978-0-02-910976-8".
+	labelledDOI   = regexp.MustCompile(`(?i)(?:\bdoi:\s*|\burn:doi:)?\b10\.\d{4,9}/[^\s"'<>?#]+`)
+	labelledPMID  = regexp.MustCompile(`(?i)\bPMID:?\s*(\d{1,9})\b`)
+	labelledArXiv = regexp.MustCompile(`(?i)\barXiv:\s*(` + arXivID + `)`)
+	labelledISBN  = regexp.MustCompile(`(?i)\b(?:urn:)?ISBN(?:-1[03])?:?\s*([0-9][0-9\- ]{8,16}[0-9X])\b`)
+
+	// arXivID matches new-style ("2101.00001v2") and old-style ("hep-th/9901001") arXiv IDs.
+	arXivID      = `\d{4}\.\d{4,5}(?:v\d+)?|[a-z-]+(?:\.[A-Z]{2})?/\d{7}(?:v\d+)?`
+	arXivPath    = regexp.MustCompile(`^/(?:abs|pdf)/(` + arXivID + `)(?:\.pdf)?/?$`)
+	pubMedPath   = regexp.MustCompile(`^/(?:pubmed/)?(\d{1,9})/?$`)
+	arXivOnly    = regexp.MustCompile(`^(?:` + arXivID + `)$`)
+	pmidOnly     = regexp.MustCompile(`^\d{1,9}$`)
+	isbnSeparate = strings.NewReplacer("-", "", " ", "")
+)
+
+// Parse reads a string that is a bare identifier, such as "doi:10.1038/nature12373", "10.1038/nature12373",
+// "arXiv:2101.00001", "PMID: 12345678" or "ISBN 978-0-02-910976-8". URLs and longer text are not identifiers.
+func Parse(s string) (Identifier, bool) {
+	s = strings.TrimSpace(s)
+	lower := strings.ToLower(s)
+	switch {
+	case s == "" || strings.Contains(lower, "://"):
+		return Identifier{}, false
+	case strings.HasPrefix(lower, "doi:") || strings.HasPrefix(lower, "urn:doi:") || strings.HasPrefix(s, "10."):
+		if doi := NormalizeDOI(s); doi != "" && labelledDOI.FindString(s) == s {
+			return Identifier{Kind: DOI, Value: doi}, true
+		}
+	case strings.HasPrefix(lower, "arxiv:"):
+		if id := NormalizeArXiv(s); id != "" {
+			return Identifier{Kind: ArXiv, Value: id}, true
+		}
+	case strings.HasPrefix(lower, "pmid"):
+		if match := labelledPMID.FindStringSubmatch(s); match != nil && match[0] == s {
+			return Identifier{Kind: PMID, Value: NormalizePMID(match[1])}, true
+		}
+	case strings.HasPrefix(lower, "isbn") || strings.HasPrefix(lower, "urn:isbn"):
+		if match := labelledISBN.FindStringSubmatch(s); match != nil && match[0] == s {
+			if isbn := NormalizeISBN(match[1]); isbn != "" {
+				return Identifier{Kind: ISBN, Value: isbn}, true
+			}
+		}
+	}
+	return Identifier{}, false
+}
+
+// FromURL reads the identifier of a source that is either a bare identifier or the URL of a resolver or landing
+// page that contains one, such as https://doi.org/10.1038/nature12373, a publisher's /doi/ page,
+// https://arxiv.org/abs/2101.00001 or https://pubmed.ncbi.nlm.nih.gov/12345678/.
+func FromURL(source string) (Identifier, bool) {
+	if id, ok := Parse(source); ok {
+		return id, true
+	}
+	u, err := url.Parse(strings.TrimSpace(source))
+	if err != nil || u.Host == "" {
+		return Identifier{}, false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	switch {
+	case host == "arxiv.org" || host == "export.arxiv.org":
+		if match := arXivPath.FindStringSubmatch(u.Path); match != nil {
+			return Identifier{Kind: ArXiv, Value: match[1]}, true
+		}
+	case host == "pubmed.ncbi.nlm.nih.gov" || host == "ncbi.nlm.nih.gov":
+		if match := pubMedPath.FindStringSubmatch(u.Path); match != nil {
+			return Identifier{Kind: PMID, Value: NormalizePMID(match[1])}, true
+		}
+	}
+	// Resolver links and many publisher pages carry the DOI in their path
+	if doi := NormalizeDOI(doiPattern.FindString(u.Path)); doi != "" {
+		return Identifier{Kind: DOI, Value: doi}, true
+	}
+	return Identifier{}, false
+}
+
+// Extract finds every labelled identifier in a text, such as the DOI, PMID, arXiv ID and ISBN of a formatted
+// reference, and returns them with the text that remains once they are taken out. DOIs are also found unlabelled.
+func Extract(text string) ([]Identifier, string) {
+	var ids []Identifier
+	seen := make(map[Identifier]bool)
+	add := func(id Identifier) {
+		if id.Value != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	text = labelledDOI.ReplaceAllStringFunc(text, func(match string) string {
+		add(Identifier{Kind: DOI, Value: NormalizeDOI(match)})
+		return ""
+	})
+	text = labelledPMID.ReplaceAllStringFunc(text, func(match string) string {
+		add(Identifier{Kind: PMID, Value: NormalizePMID(labelledPMID.FindStringSubmatch(match)[1])})
+		return ""
+	})
+	text = labelledArXiv.ReplaceAllStringFunc(text, func(match string) string {
+		add(Identifier{Kind: ArXiv, Value: NormalizeArXiv(match)})
+		return ""
+	})
+	text = labelledISBN.ReplaceAllStringFunc(text, func(match string) string {
+		isbn := NormalizeISBN(labelledISBN.FindStringSubmatch(match)[1])
+		if isbn == "" {
+			return match
+		}
+		add(Identifier{Kind: ISBN, Value: isbn})
+		return ""
+	})
+	return ids, text
+}
+
+// FindDOI finds a DOI in a string such as a URL, returning it normalized, or "" if there is none.
+func FindDOI(s string) string {
+	return NormalizeDOI(doiPattern.FindString(s))
+}
+
+// NormalizeDOI lowercases a DOI and strips resolver prefixes and trailing punctuation. DOIs are case-insensitive.
+func NormalizeDOI(doi string) string {
+	doi = strings.ToLower(strings.TrimSpace(doi))
+	for _, prefix := range []string{"https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "http://dx.doi.org/", "urn:doi:", "doi:"} {
+		doi = strings.TrimSpace(strings.TrimPrefix(doi, prefix))
+	}
+	doi = strings.TrimRight(doi, ".,;")
+	// Keep a closing parenthesis only when it closes one opened within the DOI
+	for strings.HasSuffix(doi, ")") && strings.Count(doi, "(") < strings.Count(doi, ")") {
+		doi = strings.TrimSuffix(doi, ")")
+	}
+	if !strings.HasPrefix(doi, "10.") {
+		return ""
+	}
+	return doi
+}
+
+// NormalizePMID keeps the digits of a PubMed ID without leading zeros, or returns "" if it is not one.
+func NormalizePMID(pmid string) string {
+	pmid = strings.TrimSpace(pmid)
+	for _, prefix := range []string{"PMID:", "PMID", "pmid:", "pmid"} {
+		pmid = strings.TrimSpace(strings.TrimPrefix(pmid, prefix))
+	}
+	pmid = strings.TrimLeft(pmid, "0")
+	if !pmidOnly.MatchString(pmid) {
+		return ""
+	}
+	return pmid
+}
+
+// NormalizeArXiv strips the "arXiv:" prefix from an arXiv ID, or returns "" if it is not one.
+// The version suffix is kept, as it names a specific revision of the paper.
+func NormalizeArXiv(id string) string {
+	id = strings.TrimSpace(id)
+	if len(id) >= 6 && strings.EqualFold(id[:6], "arxiv:") {
+		id = strings.TrimSpace(id[6:])
+	}
+	if !arXivOnly.MatchString(id) {
+		return ""
+	}
+	return id
+}
+
+// NormalizeISBN converts an ISBN-10 or ISBN-13, with or without hyphens, to its ISBN-13 digits, or returns ""
+// if its check digit is wrong.
+func NormalizeISBN(isbn string) string {
+	isbn = strings.ToUpper(isbnSeparate.Replace(strings.TrimSpace(isbn)))
+	switch len(isbn) {
+	case 10:
+		sum := 0
+		for i, r := range isbn {
+			digit := int(r - '0')
+			switch {
+			case r == 'X' && i == 9:
+				digit = 10
+			case r < '0' || r > '9':
+				return ""
+			}
+			sum += (10 - i) * digit
+		}
+		if sum%11 != 0 {
+			return ""
+		}
+		isbn13 := "978" + isbn[:9]
+		return isbn13 + string(rune('0'+isbn13CheckDigit(isbn13)))
+	case 13:
+		for _, r := range isbn {
+			if r < '0' || r > '9' {
+				return ""
+			}
+		}
+		if int(isbn[12]-'0') != isbn13CheckDigit(isbn[:12]) {
+			return ""
+		}
+		return isbn
+	}
+	return ""
+}
+
+// isbn13CheckDigit computes the check digit of the first 12 digits of an ISBN-13.
+func isbn13CheckDigit(digits string) int {
+	sum := 0
+	for i, r := range digits[:12] {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(r-'0')
+	}
+	return (10 - sum%10) % 10
+}
@@ -0,0 +1,90 @@
+package identifier
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParse verifies that bare identifiers are recognized and normalized, and that URLs and text are not.
+func TestParse(t *testing.T) {
+	tests := map[string]*Identifier{
+		"doi:10.1038/NATURE12373":     {Kind: DOI, Value: "10.1038/nature12373"},
+		"10.1111/j.1468-0335.2009.x.": {Kind: DOI, Value: "10.1111/j.1468-0335.2009.x"},
+		"urn:doi:10.1000/182":         {Kind: DOI, Value: "10.1000/182"},
+		"arXiv:2101.00001v2":          {Kind: ArXiv, Value: "2101.00001v2"},
+		"arxiv: hep-th/9901001":       {Kind: ArXiv, Value: "hep-th/9901001"},
+		"PMID: 012345678":             {Kind: PMID, Value: "12345678"},
+		"ISBN 978-0-02-910976-2":      {Kind: ISBN, Value: "9780029109762"},
+		"ISBN 0-02-910976-0":          {Kind: ISBN, Value: "9780029109762"},
+		"ISBN 978-0-02-910976-8":      nil,
+		"https://doi.org/10.1038/x":   nil,
+		"doi:10.1038/x and more text": nil,
+		"arXiv:not-an-id":             nil,
+		"Trust: The Social Virtues.":  nil,
+	}
+	for s, want := range tests {
+		id, ok := Parse(s)
+		switch {
+		case want == nil && ok:
+			t.Errorf("Expected %q not to be an identifier, got %v", s, id)
+		case want != nil && (!ok || id != *want):
+			t.Errorf("Expected %q to be %v, got %v (%v)", s, *want, id, ok)
+		}
+	}
+}
+
+// TestFromURL verifies that identifiers are read from resolver and landing page URLs.
+func TestFromURL(t *testing.T) {
+	tests := map[string]*Identifier{
+		"https://doi.org/10.1038/NATURE12373":                                       {Kind: DOI, Value: "10.1038/nature12373"},
+		"https://onlinelibrary.wiley.com/doi/10.1111/j.1468-0335.2009.00123.x?af=R": {Kind: DOI, Value: "10.1111/j.1468-0335.2009.00123.x"},
+		"https://arxiv.org/abs/2101.00001":                                          {Kind: ArXiv, Value: "2101.00001"},
+		"https://arxiv.org/pdf/2101.00001v3.pdf":                                    {Kind: ArXiv, Value: "2101.00001v3"},
+		"https://pubmed.ncbi.nlm.nih.gov/12345678/":                                 {Kind: PMID, Value: "12345678"},
+		"https://www.ncbi.nlm.nih.gov/pubmed/12345678":                              {Kind: PMID, Value: "12345678"},
+		"ISBN 978-0-02-910976-2":                                                    {Kind: ISBN, Value: "9780029109762"},
+		"https://www.thelancet.com/journals/lancet/article/PIIS0140-6736":           nil,
+		"https://example.org/papers/fukuyama":                                       nil,
+	}
+	for source, want := range tests {
+		id, ok := FromURL(source)
+		switch {
+		case want == nil && ok:
+			t.Errorf("Expected no identifier in %s, got %v", source, id)
+		case want != nil && (!ok || id != *want):
+			t.Errorf("Expected %v in %s, got %v (%v)", *want, source, id, ok)
+		}
+	}
+}
+
+// TestExtract verifies that the identifiers of a formatted reference are found and taken out of its text.
+func TestExtract(t *testing.T) {
+	ids, rest := Extract(`Doe, Jane (2019). "Trust". Journal. 12 (3): 45–67. doi:10.1000/XYZ123. PMID 12345678. arXiv:2101.00001. ISBN 0-02-910976-0.`)
+	want := []Identifier{
+		{Kind: DOI, Value: "10.1000/xyz123"},
+		{Kind: PMID, Value: "12345678"},
+		{Kind: ArXiv, Value: "2101.00001"},
+		{Kind: ISBN, Value: "9780029109762"},
+	}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expected %v, got %v", want, ids)
+	}
+	if rest != `Doe, Jane (2019). "Trust". Journal. 12 (3): 45–67.  . . .` {
+		t.Errorf("Expected the identifiers to be taken out, got %q", rest)
+	}
+}
+
+// TestNormalizeDOI verifies that resolver prefixes and trailing punctuation are stripped but balanced parentheses are kept.
+func TestNormalizeDOI(t *testing.T) {
+	tests := map[string]string{
+		"https://dx.doi.org/10.1000/ABC":       "10.1000/abc",
+		"10.1002/(SICI)1097-4571(199806)49:8)": "10.1002/(sici)1097-4571(199806)49:8",
+		"(see 10.1000/abc)":                    "",
+		"not a doi":                            "",
+	}
+	for doi, want := range tests {
+		if got := NormalizeDOI(doi); got != want {
+			t.Errorf("NormalizeDOI(%q) = %q, want %q", doi, got, want)
+		}
+	}
+}
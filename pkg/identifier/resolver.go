@@ -0,0 +1,61 @@
+package identifier
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultEndpoints are the resolvers of each kind of identifier. "{id}" is replaced by the normalized identifier.
+var DefaultEndpoints = map[Kind]string{
+	DOI:   "https://doi.org/{id}",
+	PMID:  "https://pubmed.ncbi.nlm.nih.gov/{id}/",
+	ArXiv: "https://arxiv.org/abs/{id}",
+	ISBN:  "https://openlibrary.org/isbn/{id}",
+}
+
+// Resolver turns identifiers into the URLs of fetchable landing pages.
+type Resolver struct {
+	endpoints map[Kind]string
+}
+
+// WithEndpoint is an option to resolve a kind of identifier through a custom endpoint, such as a proxy or a
+// mirror. "{id}" in the template is replaced by the identifier. An empty template leaves the kind unresolved.
+func WithEndpoint(kind Kind, template string) func(*Resolver) {
+	return func(r *Resolver) {
+		r.endpoints[kind] = template
+	}
+}
+
+// NewResolver creates a resolver that uses the default endpoints unless options replace them.
+func NewResolver(opts ...func(*Resolver)) *Resolver {
+	r := &Resolver{endpoints: make(map[Kind]string, len(DefaultEndpoints))}
+	for kind, template := range DefaultEndpoints {
+		r.endpoints[kind] = template
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// LandingURL returns the URL of the landing page of an identifier, and false if its kind has no endpoint.
+func (r *Resolver) LandingURL(id Identifier) (string, bool) {
+	template := r.endpoints[id.Kind]
+	if template == "" || id.Value == "" {
+		return "", false
+	}
+	// Escape the characters a URL path cannot hold, keeping the slashes of DOIs and old arXiv IDs
+	escaped := (&url.URL{Path: id.Value}).EscapedPath()
+	return strings.ReplaceAll(template, "{id}", escaped), true
+}
+
+// FetchURL returns the URL to fetch for a source: the landing page when the source is a bare identifier,
+// and the source itself otherwise.
+func (r *Resolver) FetchURL(source string) string {
+	if id, ok := Parse(source); ok {
+		if landing, ok := r.LandingURL(id); ok {
+			return landing
+		}
+	}
+	return source
+}
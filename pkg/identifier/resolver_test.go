@@ -0,0 +1,44 @@
+package identifier
+
+import (
+	"testing"
+)
+
+// TestLandingURL verifies that identifiers resolve through the default and configured endpoints.
+func TestLandingURL(t *testing.T) {
+	resolver := NewResolver(
+		WithEndpoint(ISBN, "https://books.example.org/lookup?isbn={id}"),
+		WithEndpoint(PMID, ""),
+	)
+	tests := map[Identifier]string{
+		{Kind: DOI, Value: "10.1000/a b#c"}:    "https://doi.org/10.1000/a%20b%23c",
+		{Kind: ArXiv, Value: "hep-th/9901001"}: "https://arxiv.org/abs/hep-th/9901001",
+		{Kind: ISBN, Value: "9780029109762"}:   "https://books.example.org/lookup?isbn=9780029109762",
+		{Kind: PMID, Value: "12345678"}:        "",
+	}
+	for id, want := range tests {
+		got, ok := resolver.LandingURL(id)
+		if got != want || ok != (want != "") {
+			t.Errorf("LandingURL(%v) = %q, %v, want %q", id, got, ok, want)
+		}
+	}
+	if DefaultEndpoints[PMID] == "" {
+		t.Error("Expected configuring a resolver to leave the default endpoints unchanged")
+	}
+}
+
+// TestFetchURL verifies that bare identifiers are fetched from their landing page and URLs are fetched as they are.
+func TestFetchURL(t *testing.T) {
+	resolver := NewResolver()
+	tests := map[string]string{
+		"doi:10.1038/nature12373":             "https://doi.org/10.1038/nature12373",
+		"arXiv:2101.00001":                    "https://arxiv.org/abs/2101.00001",
+		"https://doi.org/10.1038/nature12373": "https://doi.org/10.1038/nature12373",
+		"https://example.org/papers/fukuyama": "https://example.org/papers/fukuyama",
+	}
+	for source, want := range tests {
+		if got := resolver.FetchURL(source); got != want {
+			t.Errorf("FetchURL(%q) = %q, want %q", source, got, want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package linkcheck
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// parkingHosts are the services parked domains redirect to.
+var parkingHosts = []string{"sedoparking.com", "parkingcrew.net", "bodis.com", "hugedomains.com", "dan.com", "afternic.com", "above.com", "parklogic.com", "domainmarket.com"}
+
+// parkedMarker matches the text of domain parking and for-sale pages.
+var parkedMarker = regexp.MustCompile(`(?i)(this domain (name )?(is|may be) for sale|buy this domain|domain is parked|parked (free|domain)|the domain [^ ]+ is for sale|inquire about this domain|sedoparking|parkingcrew|bodis\.com|hugedomains)`)
+
+// maxParkedText is the most visible text a page can have for its body to be searched for parking markers.
+// Parking pages are short, while articles about domains may well mention them being for sale.
+const maxParkedText = 1000
+
+// notFoundMarker matches the titles and headings of error pages served with a 200 status. A bare "404" or
+// "not found" only counts when it is the whole title or heading, or part of usual error page phrasing.
+var notFoundMarker = regexp.MustCompile(`(?i)(^\s*(error\s*)?404\s*$|^\s*not found\s*$|\berror\s*404\b|\b404\s*[-:|–—]?\s*(error|not found|page)\b|page not found|\b(page|article|content|document|file|resource)( you requested| you('re| are) looking for)? (could not|cannot|can't|couldn't) be found|\b(page|article|content|document|file|resource)( you requested| you('re| are) looking for)? (no longer exists|is no longer available|does not exist|doesn't exist|has been (removed|deleted)))`)
+
+// pageText is the text of a fetched page that detection looks at.
+type pageText struct {
+	title   string
+	heading string
+	body    string
+}
+
+// readPage returns the title, first heading and visible text of a page, or nil if it cannot be parsed.
+func readPage(page string) *pageText {
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return nil
+	}
+
+	text := &pageText{}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch {
+			case n.Data == "script" || n.Data == "style":
+				return
+			case n.Data == "title" && text.title == "":
+				text.title = collapse(textOf(n))
+			case n.Data == "h1" && text.heading == "":
+				text.heading = collapse(textOf(n))
+			case n.Data == "body":
+				text.body = collapse(textOf(n))
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return text
+}
+
+// parkingHost returns the parking service a host belongs to, or "" if it is not one.
+func parkingHost(host string) string {
+	host = strings.ToLower(host)
+	for _, parking := range parkingHosts {
+		if host == parking || strings.HasSuffix(host, "."+parking) {
+			return parking
+		}
+	}
+	return ""
+}
+
+// parkedPage returns why a page looks like a parked domain, or "" if it does not. The title and first heading
+// are always searched, and the rest of the page only when it is as short as parking pages are.
+func parkedPage(text *pageText) string {
+	fields := []string{text.title, text.heading}
+	if len(text.body) <= maxParkedText {
+		fields = append(fields, text.body)
+	}
+	for _, field := range fields {
+		if match := parkedMarker.FindString(field); match != "" {
+			return "parked domain marker: " + strings.ToLower(match)
+		}
+	}
+	return ""
+}
+
+// notFoundPage returns why a page looks like an error page, or "" if it does not. Only the title
+// and the first heading are searched, since articles may mention "not found" in their text.
+func notFoundPage(text *pageText) string {
+	if notFoundMarker.MatchString(text.title) {
+		return "error page title: " + text.title
+	}
+	if notFoundMarker.MatchString(text.heading) {
+		return "error page heading: " + text.heading
+	}
+	return ""
+}
+
+// collapse trims a text and replaces each run of whitespace in it with a single space.
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// textOf concatenates the text inside a node, leaving out scripts and styles.
+func textOf(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			sb.WriteString(n.Data)
+			sb.WriteString(" ")
+		case n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style"):
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
@@ -0,0 +1,283 @@
+package linkcheck
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Verdict summarizes the state of a checked link.
+type Verdict string
+
+// Verdicts of a checked link.
+const (
+	VerdictOK          Verdict = "ok"
+	VerdictDead        Verdict = "dead"        // the server answered with a 404, 410 or 5xx status
+	VerdictBlocked     Verdict = "blocked"     // the server refused the checker with a 401, 403 or 429 status
+	VerdictSoft404     Verdict = "soft_404"    // the page answers 200 but says it was not found
+	VerdictParked      Verdict = "parked"      // the domain is parked or for sale
+	VerdictUnreachable Verdict = "unreachable" // the request failed, such as on a DNS or TLS error
+)
+
+// Redirect is one hop of a redirect chain.
+type Redirect struct {
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+}
+
+// Result is the outcome of checking a link.
+type Result struct {
+	URL       string     `json:"url"`
+	Verdict   Verdict    `json:"verdict"`
+	Status    int        `json:"status,omitempty"`
+	Method    string     `json:"method,omitempty"`
+	Redirects []Redirect `json:"redirects,omitempty"`
+	FinalURL  string     `json:"final_url,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// maxBodyBytes is how much of a page is read to look for soft 404 and parked domain markers.
+const maxBodyBytes = 256 << 10
+
+// Checker checks links with HEAD requests, falling back to GET when HEAD is refused, and reads
+// the beginning of successful pages to tell real pages from soft 404s and parked domains.
+type Checker struct {
+	client       *http.Client
+	userAgent    string
+	maxRedirects int
+	concurrency  int
+}
+
+// NewChecker creates and returns a new Checker with default settings.
+func NewChecker(opts ...func(*Checker)) *Checker {
+	checker := &Checker{
+		client:       &http.Client{Timeout: 15 * time.Second}, // Default timeout per request
+		userAgent:    "citation-scanner-linkcheck/1.0",
+		maxRedirects: 10, // Default redirect hops before giving up
+		concurrency:  8,  // Default concurrent checks
+	}
+
+	// Apply options to override defaults if provided
+	for _, opt := range opts {
+		opt(checker)
+	}
+
+	// Redirects are followed by hand to record the chain
+	client := *checker.client
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	checker.client = &client
+	return checker
+}
+
+// WithHTTPClient is an option to send the requests with a custom HTTP client.
+func WithHTTPClient(client *http.Client) func(*Checker) {
+	return func(c *Checker) {
+		c.client = client
+	}
+}
+
+// WithTimeout is an option to set the timeout of each request.
+func WithTimeout(timeout time.Duration) func(*Checker) {
+	return func(c *Checker) {
+		client := *c.client
+		client.Timeout = timeout
+		c.client = &client
+	}
+}
+
+// WithUserAgent is an option to set the User-Agent header of the requests.
+func WithUserAgent(userAgent string) func(*Checker) {
+	return func(c *Checker) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithMaxRedirects is an option to set how many redirects are followed before a link is reported as unreachable.
+func WithMaxRedirects(maxRedirects int) func(*Checker) {
+	return func(c *Checker) {
+		c.maxRedirects = maxRedirects
+	}
+}
+
+// WithConcurrency is an option to set how many links CheckAll checks at the same time. Values below one are ignored.
+func WithConcurrency(concurrency int) func(*Checker) {
+	return func(c *Checker) {
+		if concurrency > 0 {
+			c.concurrency = concurrency
+		}
+	}
+}
+
+// CheckAll checks every link and returns the results in the same order as the links.
+func (c *Checker) CheckAll(links []string) []Result {
+	results := make([]Result, len(links))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < c.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = c.Check(links[i])
+			}
+		}()
+	}
+	for i := range links {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+// Check follows a link through its redirects and classifies where it ends up.
+func (c *Checker) Check(link string) Result {
+	result := Result{URL: link}
+	current := link
+	for hop := 0; ; hop++ {
+		resp, method, err := c.request(current)
+		if err != nil {
+			result.Verdict, result.Error = VerdictUnreachable, err.Error()
+			return result
+		}
+		result.Status, result.Method = resp.StatusCode, method
+
+		location := resp.Header.Get("Location")
+		if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" {
+			result.FinalURL = current
+			c.classify(&result, resp)
+			resp.Body.Close()
+			return result
+		}
+		resp.Body.Close()
+
+		// Follow the redirect
+		next, err := resp.Request.URL.Parse(location)
+		if err != nil {
+			result.Verdict, result.Error = VerdictUnreachable, fmt.Sprintf("invalid redirect location %q: %v", location, err)
+			return result
+		}
+		result.Redirects = append(result.Redirects, Redirect{URL: current, Status: resp.StatusCode})
+		if hop >= c.maxRedirects {
+			result.Verdict, result.Error = VerdictUnreachable, fmt.Sprintf("stopped after %d redirects", c.maxRedirects)
+			return result
+		}
+		current = next.String()
+	}
+}
+
+// request sends a HEAD request, and a GET request instead when the server does not answer HEAD
+// properly or when the page is HTML whose content is needed to classify it.
+func (c *Checker) request(link string) (*http.Response, string, error) {
+	resp, err := c.send(http.MethodHead, link)
+	if err == nil {
+		switch {
+		case isRedirect(resp):
+			return resp, http.MethodHead, nil
+		case resp.StatusCode >= 400 && !headRefused(resp.StatusCode):
+			return resp, http.MethodHead, nil
+		case resp.StatusCode < 300 && !strings.Contains(resp.Header.Get("Content-Type"), "html"):
+			return resp, http.MethodHead, nil
+		}
+		resp.Body.Close()
+	}
+
+	resp, err = c.send(http.MethodGet, link)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp, http.MethodGet, nil
+}
+
+// send performs a single request without following redirects.
+func (c *Checker) send(method, link string) (*http.Response, error) {
+	req, err := http.NewRequest(method, link, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid link: %v", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the link: %v", err)
+	}
+	return resp, nil
+}
+
+// classify sets the verdict of a link from the final response of its redirect chain.
+func (c *Checker) classify(result *Result, resp *http.Response) {
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		result.Verdict = VerdictBlocked
+		return
+	case resp.StatusCode >= 400:
+		result.Verdict = VerdictDead
+		return
+	}
+
+	result.Verdict = VerdictOK
+	finalURL, err := url.Parse(result.FinalURL)
+	if err != nil {
+		return
+	}
+	if host := parkingHost(finalURL.Hostname()); host != "" {
+		result.Verdict, result.Reason = VerdictParked, "redirected to the parking service "+host
+		return
+	}
+	if len(result.Redirects) > 0 && isRoot(finalURL) && !isRoot(parseOrNil(result.URL)) {
+		result.Verdict, result.Reason = VerdictSoft404, "a deep link redirected to the home page"
+		return
+	}
+
+	if resp.Request.Method != http.MethodGet || !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return
+	}
+	text := readPage(string(body))
+	if text == nil {
+		return
+	}
+	if reason := parkedPage(text); reason != "" {
+		result.Verdict, result.Reason = VerdictParked, reason
+	} else if reason := notFoundPage(text); reason != "" {
+		result.Verdict, result.Reason = VerdictSoft404, reason
+	}
+}
+
+// isRedirect reports whether a response redirects elsewhere.
+func isRedirect(resp *http.Response) bool {
+	return resp.StatusCode >= 300 && resp.StatusCode < 400 && resp.Header.Get("Location") != ""
+}
+
+// headRefused reports whether a status is how servers commonly answer HEAD requests they do not support.
+func headRefused(status int) bool {
+	switch status {
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented, http.StatusForbidden, http.StatusBadRequest, http.StatusNotFound:
+		return true
+	}
+	return false
+}
+
+// isRoot reports whether a URL points at the home page of its site.
+func isRoot(u *url.URL) bool {
+	return u != nil && (u.Path == "" || u.Path == "/") && u.RawQuery == ""
+}
+
+// parseOrNil parses a URL, returning nil if it is invalid.
+func parseOrNil(rawURL string) *url.URL {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	return u
+}
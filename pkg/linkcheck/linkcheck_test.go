@@ -0,0 +1,187 @@
+package linkcheck
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestServer serves a page for each kind of link the checker must recognize.
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "<html><head><title>Home</title></head><body><h1>Welcome</h1></body></html>")
+	})
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><head><title>Trust and prosperity</title></head><body><h1>Trust</h1><p>The cause was not found in the data.</p></body></html>")
+	})
+	mux.HandleFunc("/head-refused", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		fmt.Fprint(w, "<html><head><title>Report</title></head><body></body></html>")
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/renamed", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/renamed", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/article", http.StatusFound)
+	})
+	mux.HandleFunc("/papers/2009/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/", http.StatusFound)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	mux.HandleFunc("/forbidden", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/soft", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><head><title>Page Not Found | Example News</title></head><body></body></html>")
+	})
+	mux.HandleFunc("/parked", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><head><title>example-research.com</title></head><body><p>This domain is for sale! Inquire now.</p></body></html>")
+	})
+	mux.HandleFunc("/report.pdf", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("Expected only a HEAD request for a PDF, got %s", r.Method)
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestCheck verifies the verdict, status and method of each kind of link.
+func TestCheck(t *testing.T) {
+	server := newTestServer(t)
+	checker := NewChecker(WithMaxRedirects(3))
+
+	tests := []struct {
+		path    string
+		verdict Verdict
+		status  int
+		method  string
+	}{
+		{"/article", VerdictOK, http.StatusOK, http.MethodGet},
+		{"/head-refused", VerdictOK, http.StatusOK, http.MethodGet},
+		{"/report.pdf", VerdictOK, http.StatusOK, http.MethodHead},
+		{"/moved", VerdictOK, http.StatusOK, http.MethodGet},
+		{"/gone", VerdictDead, http.StatusGone, http.MethodHead},
+		{"/missing", VerdictDead, http.StatusNotFound, http.MethodGet},
+		{"/forbidden", VerdictBlocked, http.StatusForbidden, http.MethodGet},
+		{"/soft", VerdictSoft404, http.StatusOK, http.MethodGet},
+		{"/papers/2009/old", VerdictSoft404, http.StatusOK, http.MethodGet},
+		{"/parked", VerdictParked, http.StatusOK, http.MethodGet},
+		{"/loop", VerdictUnreachable, http.StatusFound, http.MethodHead},
+	}
+
+	for _, tt := range tests {
+		result := checker.Check(server.URL + tt.path)
+		if result.Verdict != tt.verdict || result.Status != tt.status || result.Method != tt.method {
+			t.Errorf("%s: expected %s with status %d via %s, got %s with status %d via %s (%s%s)", tt.path, tt.verdict, tt.status, tt.method, result.Verdict, result.Status, result.Method, result.Reason, result.Error)
+		}
+	}
+}
+
+// TestCheckRedirectChain verifies that every hop of a redirect chain is recorded along with the final URL.
+func TestCheckRedirectChain(t *testing.T) {
+	server := newTestServer(t)
+	result := NewChecker().Check(server.URL + "/moved")
+
+	if len(result.Redirects) != 2 {
+		t.Fatalf("Expected 2 redirects, got %+v", result.Redirects)
+	}
+	if result.Redirects[0].URL != server.URL+"/moved" || result.Redirects[0].Status != http.StatusMovedPermanently {
+		t.Errorf("Unexpected first hop %+v", result.Redirects[0])
+	}
+	if result.Redirects[1].URL != server.URL+"/renamed" || result.Redirects[1].Status != http.StatusFound {
+		t.Errorf("Unexpected second hop %+v", result.Redirects[1])
+	}
+	if result.FinalURL != server.URL+"/article" {
+		t.Errorf("Expected the final URL to be %s/article, got %s", server.URL, result.FinalURL)
+	}
+}
+
+// TestCheckAll verifies that results keep the order of the links and that unreachable hosts are reported.
+func TestCheckAll(t *testing.T) {
+	server := newTestServer(t)
+	links := []string{server.URL + "/article", "http://127.0.0.1:1/unreachable", server.URL + "/gone"}
+
+	results := NewChecker(WithConcurrency(2)).CheckAll(links)
+	want := []Verdict{VerdictOK, VerdictUnreachable, VerdictDead}
+	for i, result := range results {
+		if result.URL != links[i] || result.Verdict != want[i] {
+			t.Errorf("Expected result %d to be %s for %s, got %s for %s", i, want[i], links[i], result.Verdict, result.URL)
+		}
+	}
+	if results[1].Error == "" {
+		t.Error("Expected an error for the unreachable link")
+	}
+}
+
+// TestParkingHost verifies that redirects to parking services are recognized by their host.
+func TestParkingHost(t *testing.T) {
+	if parkingHost("www.SedoParking.com") != "sedoparking.com" {
+		t.Error("Expected a subdomain of a parking service to be recognized")
+	}
+	if parkingHost("notdan.com") != "" {
+		t.Error("Expected a host that only ends with the same letters not to be recognized")
+	}
+}
+
+// TestNotFoundPage verifies that only error page phrasing in a title or heading marks a soft 404.
+func TestNotFoundPage(t *testing.T) {
+	tests := []struct {
+		title   string
+		soft404 bool
+	}{
+		{"Page Not Found | Example News", true},
+		{"404", true},
+		{"404 - Not Found", true},
+		{"Error 404", true},
+		{"Not Found", true},
+		{"Sorry, the page you are looking for does not exist", true},
+		{"The Tomb That Was Never Found", false},
+		{"Why the Missing Link Does Not Exist", false},
+		{"Route 404 reopens after repairs", false},
+		{"Dark matter not found in new survey", false},
+	}
+	for _, tt := range tests {
+		page := "<html><head><title>" + tt.title + "</title></head><body><h1>News</h1></body></html>"
+		if got := notFoundPage(readPage(page)) != ""; got != tt.soft404 {
+			t.Errorf("Title %q: expected soft 404 %v, got %v", tt.title, tt.soft404, got)
+		}
+	}
+}
+
+// TestParkedPage verifies that parking markers are only searched for in the body of short pages.
+func TestParkedPage(t *testing.T) {
+	short := "<html><head><title>example.com</title></head><body><p>Buy this domain today.</p></body></html>"
+	if parkedPage(readPage(short)) == "" {
+		t.Error("Expected a short page offering the domain for sale to be parked")
+	}
+
+	article := "<html><head><title>The domain name market</title></head><body><h1>Domains</h1><p>" +
+		strings.Repeat("Investors trade names like stocks. ", 40) + "One listing simply read: buy this domain.</p></body></html>"
+	if reason := parkedPage(readPage(article)); reason != "" {
+		t.Errorf("Expected an article quoting a parking page not to be parked, got %q", reason)
+	}
+
+	titled := "<html><head><title>This domain is for sale</title></head><body><p>" + strings.Repeat("Lorem ipsum dolor sit amet. ", 60) + "</p></body></html>"
+	if parkedPage(readPage(titled)) == "" {
+		t.Error("Expected a parking marker in the title to be found on a long page")
+	}
+}
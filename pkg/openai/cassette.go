@@ -0,0 +1,219 @@
+package openai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// CassetteMode selects whether a Cassette records new interactions or replays stored ones.
+type CassetteMode string
+
+const (
+	// CassetteRecord forwards prompts to the wrapped client and stores every response.
+	CassetteRecord CassetteMode = "record"
+	// CassetteReplay serves stored responses and fails on prompts it has not seen.
+	CassetteReplay CassetteMode = "replay"
+)
+
+// Cassette is a ChatClient and Embedder that stores prompt-hash→response pairs on disk so runs can be replayed offline.
+type Cassette struct {
+	path         string
+	mode         CassetteMode
+	next         ChatClient
+	mu           sync.Mutex
+	interactions map[string]cassetteInteraction
+}
+
+// cassetteInteraction is a single recorded prompt and its response.
+type cassetteInteraction struct {
+	Hash     string      `json:"hash"`
+	Prompt   string      `json:"prompt"`
+	Response string      `json:"response"`
+	Vectors  [][]float64 `json:"vectors,omitempty"`
+	Model    string      `json:"model,omitempty"`
+	Usage    Usage       `json:"usage"`
+}
+
+// cassetteFile is the on-disk layout of a cassette.
+type cassetteFile struct {
+	Interactions []cassetteInteraction `json:"interactions"`
+}
+
+// NewRecorder wraps a client and records its responses to the cassette at path, keeping any existing entries.
+// Embeddings can only be recorded when the client is also an Embedder.
+func NewRecorder(next ChatClient, path string) (*Cassette, error) {
+	if next == nil {
+		return nil, fmt.Errorf("a client is required to record a cassette")
+	}
+	interactions, err := loadCassette(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if interactions == nil {
+		interactions = make(map[string]cassetteInteraction)
+	}
+	return &Cassette{path: path, mode: CassetteRecord, next: next, interactions: interactions}, nil
+}
+
+// NewReplayer loads the cassette at path and serves its stored responses without network access.
+func NewReplayer(path string) (*Cassette, error) {
+	interactions, err := loadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Cassette{path: path, mode: CassetteReplay, interactions: interactions}, nil
+}
+
+// Mode reports whether the cassette is recording or replaying.
+func (c *Cassette) Mode() CassetteMode {
+	return c.mode
+}
+
+// Complete answers the prompt from the cassette, recording it first when in record mode.
+func (c *Cassette) Complete(prompt string) (*ChatCompletion, error) {
+	hash := PromptHash(prompt)
+
+	c.mu.Lock()
+	interaction, found := c.interactions[hash]
+	c.mu.Unlock()
+
+	if c.mode == CassetteReplay {
+		if !found || interaction.Vectors != nil {
+			return nil, fmt.Errorf("cassette %s has no recorded response for prompt %s", c.path, hash)
+		}
+		return &ChatCompletion{Content: interaction.Response, Model: interaction.Model, Usage: interaction.Usage}, nil
+	}
+
+	completion, err := c.next.Complete(prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions[hash] = cassetteInteraction{
+		Hash:     hash,
+		Prompt:   prompt,
+		Response: completion.Content,
+		Model:    completion.Model,
+		Usage:    completion.Usage,
+	}
+	if err := c.save(); err != nil {
+		return nil, err
+	}
+	return completion, nil
+}
+
+// Embed answers an embedding request from the cassette, recording it first when in record mode.
+func (c *Cassette) Embed(texts []string) (*Embeddings, error) {
+	prompt, err := embeddingPrompt(texts)
+	if err != nil {
+		return nil, err
+	}
+	hash := PromptHash(prompt)
+
+	c.mu.Lock()
+	interaction, found := c.interactions[hash]
+	c.mu.Unlock()
+
+	if c.mode == CassetteReplay {
+		if !found || len(interaction.Vectors) != len(texts) {
+			return nil, fmt.Errorf("cassette %s has no recorded embeddings for prompt %s", c.path, hash)
+		}
+		return &Embeddings{Vectors: interaction.Vectors, Model: interaction.Model, Usage: interaction.Usage}, nil
+	}
+
+	embedder, ok := c.next.(Embedder)
+	if !ok {
+		return nil, fmt.Errorf("the recorded client does not support embeddings")
+	}
+	embeddings, err := embedder.Embed(texts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions[hash] = cassetteInteraction{
+		Hash:    hash,
+		Prompt:  prompt,
+		Vectors: embeddings.Vectors,
+		Model:   embeddings.Model,
+		Usage:   embeddings.Usage,
+	}
+	if err := c.save(); err != nil {
+		return nil, err
+	}
+	return embeddings, nil
+}
+
+// embeddingPrompt returns the text under which a batch of texts to embed is stored, which cannot be mistaken for a chat prompt.
+func embeddingPrompt(texts []string) (string, error) {
+	data, err := json.Marshal(texts)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode texts to embed: %v", err)
+	}
+	return "embed:" + string(data), nil
+}
+
+// PromptHash returns the key under which a prompt is stored in a cassette.
+func PromptHash(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// save writes the cassette to disk sorted by hash so that re-recording produces stable diffs.
+// The caller must hold c.mu.
+func (c *Cassette) save() error {
+	file := cassetteFile{Interactions: make([]cassetteInteraction, 0, len(c.interactions))}
+	for _, interaction := range c.interactions {
+		file.Interactions = append(file.Interactions, interaction)
+	}
+	sort.Slice(file.Interactions, func(i, j int) bool {
+		return file.Interactions[i].Hash < file.Interactions[j].Hash
+	})
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %v", err)
+	}
+
+	// Write to a temporary file first so an interrupted run never leaves a truncated cassette
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %v", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return fmt.Errorf("failed to write cassette: %v", err)
+	}
+	return nil
+}
+
+// loadCassette reads the interactions stored at path, keyed by prompt hash.
+func loadCassette(path string) (map[string]cassetteInteraction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var file cassetteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %v", path, err)
+	}
+
+	interactions := make(map[string]cassetteInteraction, len(file.Interactions))
+	for _, interaction := range file.Interactions {
+		interactions[interaction.Hash] = interaction
+	}
+	return interactions, nil
+}
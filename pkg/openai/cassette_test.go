@@ -0,0 +1,147 @@
+package openai
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// stubClient answers prompts from a fixed map and counts how often it was called.
+type stubClient struct {
+	responses map[string]string
+	calls     int
+}
+
+func (s *stubClient) Complete(prompt string) (*ChatCompletion, error) {
+	s.calls++
+	response, ok := s.responses[prompt]
+	if !ok {
+		return nil, fmt.Errorf("unexpected prompt %q", prompt)
+	}
+	return &ChatCompletion{
+		Content: response,
+		Model:   "stub-model",
+		Usage:   Usage{PromptTokens: int64(len(prompt)), CompletionTokens: int64(len(response)), TotalTokens: int64(len(prompt) + len(response))},
+	}, nil
+}
+
+// TestCassetteRecordAndReplay records responses through a stub client and replays them from disk.
+func TestCassetteRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	stub := &stubClient{responses: map[string]string{
+		"first prompt":  `{"claims": []}`,
+		"second prompt": `{"claims": [{"claim": "x", "sources": []}]}`,
+	}}
+
+	recorder, err := NewRecorder(stub, path)
+	if err != nil {
+		t.Fatalf("Failed to create recorder: %v", err)
+	}
+	for prompt, want := range stub.responses {
+		got, err := recorder.Complete(prompt)
+		if err != nil {
+			t.Fatalf("Failed to record prompt %q: %v", prompt, err)
+		}
+		if got.Content != want {
+			t.Errorf("Expected recorded response %q, got %q", want, got.Content)
+		}
+	}
+
+	replayer, err := NewReplayer(path)
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	for prompt, want := range stub.responses {
+		got, err := replayer.Complete(prompt)
+		if err != nil {
+			t.Fatalf("Failed to replay prompt %q: %v", prompt, err)
+		}
+		if got.Content != want {
+			t.Errorf("Expected replayed response %q, got %q", want, got.Content)
+		}
+		if got.Model != "stub-model" || got.Usage.TotalTokens != int64(len(prompt)+len(want)) {
+			t.Errorf("Expected replayed model and usage to match the recording, got %q and %+v", got.Model, got.Usage)
+		}
+	}
+	if stub.calls != len(stub.responses) {
+		t.Errorf("Expected the stub to be called %d times, got %d", len(stub.responses), stub.calls)
+	}
+}
+
+// TestCassetteReplayUnknownPrompt verifies that replay mode fails instead of reaching the network.
+func TestCassetteReplayUnknownPrompt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder, err := NewRecorder(&stubClient{responses: map[string]string{"known": "ok"}}, path)
+	if err != nil {
+		t.Fatalf("Failed to create recorder: %v", err)
+	}
+	if _, err := recorder.Complete("known"); err != nil {
+		t.Fatalf("Failed to record prompt: %v", err)
+	}
+
+	replayer, err := NewReplayer(path)
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	if _, err := replayer.Complete("unknown"); err == nil {
+		t.Error("Expected an error for an unrecorded prompt, got nil")
+	}
+}
+
+// TestCassetteReplayMissingFile verifies that a missing cassette is reported when replaying.
+func TestCassetteReplayMissingFile(t *testing.T) {
+	if _, err := NewReplayer(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing cassette, got nil")
+	}
+}
+
+// stubEmbedder is a stub client that also embeds each text as a vector of its length.
+type stubEmbedder struct {
+	stubClient
+}
+
+func (s *stubEmbedder) Embed(texts []string) (*Embeddings, error) {
+	s.calls++
+	embeddings := &Embeddings{Model: "stub-embedding", Usage: Usage{PromptTokens: int64(len(texts)), TotalTokens: int64(len(texts))}}
+	for _, text := range texts {
+		embeddings.Vectors = append(embeddings.Vectors, []float64{float64(len(text)), 1})
+	}
+	return embeddings, nil
+}
+
+// TestCassetteEmbeddings records embeddings through a stub embedder and replays them from disk.
+func TestCassetteEmbeddings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	stub := &stubEmbedder{}
+	texts := []string{"first claim", "a second claim"}
+
+	recorder, err := NewRecorder(stub, path)
+	if err != nil {
+		t.Fatalf("Failed to create recorder: %v", err)
+	}
+	if _, err := recorder.Embed(texts); err != nil {
+		t.Fatalf("Failed to record embeddings: %v", err)
+	}
+
+	replayer, err := NewReplayer(path)
+	if err != nil {
+		t.Fatalf("Failed to load cassette: %v", err)
+	}
+	got, err := replayer.Embed(texts)
+	if err != nil {
+		t.Fatalf("Failed to replay embeddings: %v", err)
+	}
+	if len(got.Vectors) != 2 || got.Vectors[1][0] != float64(len(texts[1])) || got.Model != "stub-embedding" || got.Usage.TotalTokens != 2 {
+		t.Errorf("Expected the replayed embeddings to match the recording, got %+v", got)
+	}
+	if stub.calls != 1 {
+		t.Errorf("Expected the stub to be called once, got %d", stub.calls)
+	}
+
+	if _, err := replayer.Embed([]string{"unseen claim"}); err == nil {
+		t.Error("Expected an error for texts that were not recorded")
+	}
+	if _, err := replayer.Complete(`embed:["first claim","a second claim"]`); err == nil {
+		t.Error("Expected recorded embeddings not to be served as a chat completion")
+	}
+}
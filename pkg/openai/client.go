@@ -9,6 +9,37 @@ import (
 	"github.com/openai/openai-go/option"
 )
 
+// ChatClient is implemented by anything that can answer a single chat prompt.
+type ChatClient interface {
+	Complete(prompt string) (*ChatCompletion, error)
+}
+
+// ChatCompletion is the content of a chat response together with the model that produced it and its token usage.
+type ChatCompletion struct {
+	Content string `json:"content"`
+	Model   string `json:"model"`
+	Usage   Usage  `json:"usage"`
+}
+
+// Usage is the number of tokens consumed by a chat completion.
+type Usage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+// Embedder is implemented by clients that can turn texts into embedding vectors.
+type Embedder interface {
+	Embed(texts []string) (*Embeddings, error)
+}
+
+// Embeddings are the vectors for a batch of texts, in the same order as the texts, with the model and token usage.
+type Embeddings struct {
+	Vectors [][]float64
+	Model   string
+	Usage   Usage
+}
+
 // OpenAIClient is a struct that handles OpenAI API interactions.
 type OpenAIClient struct {
 	client      *openai.Client
@@ -16,6 +47,8 @@ type OpenAIClient struct {
 	systemRole  string
 	temperature float64
 	maxTokens   int64
+
+	embeddingModel openai.EmbeddingModel
 }
 
 // NewClient creates and returns a new OpenAIClient with default settings.
@@ -31,6 +64,8 @@ func NewClient(apiKey string, opts ...func(*OpenAIClient)) *OpenAIClient {
 		systemRole:  "You are a helpful assistant.", // Default role
 		temperature: 0.05,                           // Default temperature
 		maxTokens:   16384,                          // Default max tokens in the return
+
+		embeddingModel: openai.EmbeddingModelTextEmbedding3Small, // Default embedding model
 	}
 
 	// Apply options to override defaults if provided
@@ -69,8 +104,24 @@ func WithMaxTokens(tokens int64) func(*OpenAIClient) {
 	}
 }
 
+// WithEmbeddingModel is an option to set a custom embedding model.
+func WithEmbeddingModel(model openai.EmbeddingModel) func(*OpenAIClient) {
+	return func(c *OpenAIClient) {
+		c.embeddingModel = model
+	}
+}
+
 // SendChatRequest sends a chat request to the OpenAI API and returns the response.
 func (c *OpenAIClient) SendChatRequest(prompt string) (string, error) {
+	completion, err := c.Complete(prompt)
+	if err != nil {
+		return "", err
+	}
+	return completion.Content, nil
+}
+
+// Complete sends a chat request to the OpenAI API and returns the response along with its token usage.
+func (c *OpenAIClient) Complete(prompt string) (*ChatCompletion, error) {
 	chatCompletion, err := c.client.Chat.Completions.New(
 		context.TODO(),
 		openai.ChatCompletionNewParams{
@@ -84,11 +135,49 @@ func (c *OpenAIClient) SendChatRequest(prompt string) (string, error) {
 		},
 	)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %v", err)
+		return nil, fmt.Errorf("failed to send request: %v", err)
 	}
 
 	if len(chatCompletion.Choices) > 0 {
-		return chatCompletion.Choices[0].Message.Content, nil
+		return &ChatCompletion{
+			Content: chatCompletion.Choices[0].Message.Content,
+			Model:   chatCompletion.Model,
+			Usage: Usage{
+				PromptTokens:     chatCompletion.Usage.PromptTokens,
+				CompletionTokens: chatCompletion.Usage.CompletionTokens,
+				TotalTokens:      chatCompletion.Usage.TotalTokens,
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("no response received")
+}
+
+// Embed sends the texts to the OpenAI embeddings API and returns one vector per text.
+func (c *OpenAIClient) Embed(texts []string) (*Embeddings, error) {
+	response, err := c.client.Embeddings.New(
+		context.TODO(),
+		openai.EmbeddingNewParams{
+			Input: openai.F[openai.EmbeddingNewParamsInputUnion](openai.EmbeddingNewParamsInputArrayOfStrings(texts)),
+			Model: openai.F(c.embeddingModel),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send embedding request: %v", err)
+	}
+	if len(response.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, received %d", len(texts), len(response.Data))
+	}
+
+	embeddings := &Embeddings{
+		Vectors: make([][]float64, len(texts)),
+		Model:   response.Model,
+		Usage:   Usage{PromptTokens: response.Usage.PromptTokens, TotalTokens: response.Usage.TotalTokens},
+	}
+	for _, data := range response.Data {
+		if data.Index < 0 || int(data.Index) >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", data.Index)
+		}
+		embeddings.Vectors[data.Index] = data.Embedding
 	}
-	return "", fmt.Errorf("no response received")
+	return embeddings, nil
 }
@@ -0,0 +1,145 @@
+package webscraper
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// PublicationDates holds when a page was first published and last modified, as declared by the page.
+// Either may be zero when the page does not say.
+type PublicationDates struct {
+	Published time.Time
+	Modified  time.Time
+}
+
+// publishedMeta and modifiedMeta are the meta tag names and properties that carry the dates of a page.
+var (
+	publishedMeta = map[string]bool{
+		"article:published_time": true, "og:published_time": true, "citation_publication_date": true,
+		"citation_date": true, "citation_online_date": true, "dc.date": true, "dc.date.issued": true,
+		"dcterms.issued": true, "date": true, "pubdate": true, "publish_date": true,
+	}
+	modifiedMeta = map[string]bool{
+		"article:modified_time": true, "og:updated_time": true, "dc.date.modified": true,
+		"dcterms.modified": true, "last-modified": true,
+	}
+)
+
+// dateLayouts are the date formats accepted in page metadata, most specific first.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2006/01/02",
+	"2006-01",
+	"2006/01",
+	"2006",
+	"January 2, 2006",
+	"2 January 2006",
+}
+
+// ExtractPublicationDates reads the publication and modification dates of a page from its meta tags,
+// its JSON-LD structured data and its <time pubdate> elements. Meta tags take precedence.
+func ExtractPublicationDates(page string) PublicationDates {
+	var dates, structured PublicationDates
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return dates
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "meta":
+				name := strings.ToLower(attribute(n, "name") + attribute(n, "property") + attribute(n, "itemprop"))
+				setDate(&dates.Published, publishedMeta[name] || name == "datepublished", attribute(n, "content"))
+				setDate(&dates.Modified, modifiedMeta[name] || name == "datemodified", attribute(n, "content"))
+			case "time":
+				if _, ok := hasAttribute(n, "pubdate"); ok {
+					setDate(&structured.Published, true, attribute(n, "datetime"))
+				}
+			case "script":
+				if strings.EqualFold(attribute(n, "type"), "application/ld+json") && n.FirstChild != nil {
+					var data interface{}
+					if json.Unmarshal([]byte(n.FirstChild.Data), &data) == nil {
+						readStructuredDates(data, &structured)
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	if dates.Published.IsZero() {
+		dates.Published = structured.Published
+	}
+	if dates.Modified.IsZero() {
+		dates.Modified = structured.Modified
+	}
+	return dates
+}
+
+// readStructuredDates looks for datePublished and dateModified anywhere in a JSON-LD document, including its @graph.
+func readStructuredDates(data interface{}, dates *PublicationDates) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		if s, ok := v["datePublished"].(string); ok {
+			setDate(&dates.Published, true, s)
+		}
+		if s, ok := v["dateModified"].(string); ok {
+			setDate(&dates.Modified, true, s)
+		}
+		for _, child := range v {
+			readStructuredDates(child, dates)
+		}
+	case []interface{}:
+		for _, child := range v {
+			readStructuredDates(child, dates)
+		}
+	}
+}
+
+// setDate parses value into date if wanted and no date was found yet.
+func setDate(date *time.Time, wanted bool, value string) {
+	if !wanted || !date.IsZero() {
+		return
+	}
+	if parsed, ok := ParseDate(value); ok {
+		*date = parsed
+	}
+}
+
+// ParseDate parses a date in one of the formats used in page metadata, such as RFC 3339 or "2006-01-02".
+func ParseDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// attribute returns the value of an attribute of an element, or "" if it is not set.
+func attribute(n *html.Node, key string) string {
+	value, _ := hasAttribute(n, key)
+	return value
+}
+
+// hasAttribute returns the value of an attribute of an element and whether it is set.
+func hasAttribute(n *html.Node, key string) (string, bool) {
+	for _, attr := range n.Attr {
+		if strings.EqualFold(attr.Key, key) {
+			return attr.Val, true
+		}
+	}
+	return "", false
+}
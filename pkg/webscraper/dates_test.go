@@ -0,0 +1,41 @@
+package webscraper
+
+import (
+	"testing"
+	"time"
+)
+
+// TestExtractPublicationDates verifies that dates are read from meta tags, JSON-LD and <time> elements, with meta tags first.
+func TestExtractPublicationDates(t *testing.T) {
+	tests := []struct {
+		name      string
+		page      string
+		published string
+		modified  string
+	}{
+		{"meta", `<html><head>
+			<meta property="article:published_time" content="2015-06-01T09:00:00+02:00">
+			<meta property="article:modified_time" content="2018-03-01">
+			<script type="application/ld+json">{"datePublished": "2001-01-01"}</script>
+		</head><body></body></html>`, "2015-06-01", "2018-03-01"},
+		{"scholarly", `<html><head><meta name="citation_publication_date" content="1995/08"></head></html>`, "1995-08-01", ""},
+		{"json-ld", `<html><head><script type="application/ld+json">
+			{"@context": "https://schema.org", "@graph": [{"@type": "WebPage"}, {"@type": "NewsArticle", "datePublished": "2021-02-01T10:00:00Z", "dateModified": "2021-02-03T08:00:00Z"}]}
+		</script></head></html>`, "2021-02-01", "2021-02-03"},
+		{"time", `<html><body><article><time pubdate datetime="2012-11-05">5 November</time></article></body></html>`, "2012-11-05", ""},
+		{"none", `<html><head><meta name="date" content="last Tuesday"></head></html>`, "", ""},
+	}
+
+	format := func(date time.Time) string {
+		if date.IsZero() {
+			return ""
+		}
+		return date.Format("2006-01-02")
+	}
+	for _, tt := range tests {
+		dates := ExtractPublicationDates(tt.page)
+		if format(dates.Published) != tt.published || format(dates.Modified) != tt.modified {
+			t.Errorf("%s: expected published %q and modified %q, got %q and %q", tt.name, tt.published, tt.modified, format(dates.Published), format(dates.Modified))
+		}
+	}
+}
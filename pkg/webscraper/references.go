@@ -0,0 +1,202 @@
+package webscraper
+
+import (
+	"citation-scanner/pkg/archive"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Reference is an entry of a page's reference list, with the metadata of the citation template it was rendered from.
+type Reference struct {
+	Index      int       `json:"index"`
+	ID         string    `json:"id,omitempty"`
+	Text       string    `json:"text"`
+	URL        string    `json:"url,omitempty"`
+	ArchiveURL string    `json:"archive_url,omitempty"`
+	AccessDate time.Time `json:"access_date,omitempty"`
+	Dead       bool      `json:"dead,omitempty"`
+	Italics    []string  `json:"italics,omitempty"`
+}
+
+var (
+	// BareURL matches the links written out as text in references without an <a> tag.
+	BareURL = regexp.MustCompile(`https?://[^\s<>"]+[^\s<>".,;:)\]]`)
+	// retrieved matches the access date rendered by citation templates, such as "Retrieved 12 March 2020".
+	retrieved = regexp.MustCompile(`(?i)(?:retrieved|accessed)(?: on)?:?\s+([^.]+)`)
+	// spaces matches runs of whitespace.
+	spaces = regexp.MustCompile(`\s+`)
+)
+
+// link is a link of a reference, in the order it appears.
+type link struct {
+	href     string
+	sameHost bool
+}
+
+// References reads the entries of a page's reference lists: the <li> entries of lists whose class
+// contains "references", as rendered by Wikipedia, or else the entries holding a <cite class="citation">.
+// Each entry is numbered from 1 in page order, matching the [n] markers of the text.
+//
+// Citation templates with an archive link render the title as a link to the archive and the live URL as
+// "the original" when the live URL is dead (url-status=dead), so a reference whose first link is the
+// archive is marked dead.
+func References(page, pageURL string) ([]Reference, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := findEntries(doc, func(n *html.Node) bool {
+		return (n.Data == "ol" || n.Data == "ul") && hasClass(n, "references")
+	})
+	if len(entries) == 0 {
+		entries = findEntries(doc, func(n *html.Node) bool {
+			return n.Data == "cite" && hasClass(n, "citation")
+		})
+	}
+
+	references := []Reference{}
+	for i, entry := range entries {
+		references = append(references, readReference(entry, i+1, base))
+	}
+	return references, nil
+}
+
+// findEntries returns the <li> entries of the lists matched by isList, or, when isList matches citations,
+// the <li> containing each citation, in document order.
+func findEntries(doc *html.Node, isList func(*html.Node) bool) []*html.Node {
+	var entries []*html.Node
+	var walk func(n *html.Node, item *html.Node)
+	walk = func(n *html.Node, item *html.Node) {
+		if n.Type == html.ElementNode {
+			if n.Data == "li" {
+				item = n
+			}
+			if isList(n) {
+				if n.Data == "cite" {
+					if item != nil && (len(entries) == 0 || entries[len(entries)-1] != item) {
+						entries = append(entries, item)
+					}
+					return
+				}
+				for c := n.FirstChild; c != nil; c = c.NextSibling {
+					if c.Type == html.ElementNode && c.Data == "li" {
+						entries = append(entries, c)
+					}
+				}
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, item)
+		}
+	}
+	walk(doc, nil)
+	return entries
+}
+
+// readReference reads the text, links and template metadata of a reference entry.
+func readReference(entry *html.Node, index int, base *url.URL) Reference {
+	reference := Reference{Index: index, ID: attribute(entry, "id")}
+
+	var sb strings.Builder
+	var links []link
+	var accessDate string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			sb.WriteString(n.Data)
+			return
+		case n.Type != html.ElementNode:
+		case hasClass(n, "mw-cite-backlink"):
+			// Skip the "^ a b" links back to the text
+			return
+		case hasClass(n, "reference-accessdate"):
+			accessDate = textContent(n)
+		case n.Data == "i" || n.Data == "em":
+			// Citation styles set the work or the journal in italics
+			if text := textContent(n); text != "" {
+				reference.Italics = append(reference.Italics, text)
+			}
+		case n.Data == "a":
+			if href, err := base.Parse(strings.TrimSpace(attribute(n, "href"))); err == nil && (href.Scheme == "http" || href.Scheme == "https") {
+				links = append(links, link{href: href.String(), sameHost: href.Host == base.Host})
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(entry)
+	reference.Text = strings.TrimSpace(spaces.ReplaceAllString(sb.String(), " "))
+
+	// References written as plain text carry their links in the text
+	if len(links) == 0 {
+		for _, href := range BareURL.FindAllString(reference.Text, -1) {
+			links = append(links, link{href: href})
+		}
+	}
+
+	// Links within the site, such as the author's article, only stand in for the source when there is no other link
+	var sameHost string
+	for _, l := range links {
+		switch {
+		case archive.IsArchiveURL(l.href):
+			if reference.ArchiveURL == "" {
+				reference.ArchiveURL = l.href
+				reference.Dead = reference.URL == ""
+			}
+		case l.sameHost:
+			if sameHost == "" {
+				sameHost = l.href
+			}
+		case reference.URL == "":
+			reference.URL = l.href
+		}
+	}
+	if reference.URL == "" && reference.ArchiveURL == "" {
+		reference.URL = sameHost
+	}
+	if reference.URL == "" && reference.ArchiveURL != "" {
+		// Unfit and usurped URLs are not linked at all, only their archived copy
+		reference.URL = archive.SnapshotFromURL(reference.ArchiveURL).OriginalURL
+		reference.Dead = reference.URL != ""
+	}
+
+	if accessDate == "" {
+		if match := retrieved.FindStringSubmatch(reference.Text); match != nil {
+			accessDate = match[1]
+		}
+	}
+	accessDate = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(accessDate), "Retrieved"), "Accessed"))
+	if date, ok := ParseDate(strings.TrimSuffix(accessDate, ".")); ok {
+		reference.AccessDate = date
+	}
+	return reference
+}
+
+// hasClass reports whether an element has a class.
+func hasClass(n *html.Node, class string) bool {
+	for _, c := range strings.Fields(attribute(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// textContent concatenates the text inside a node.
+func textContent(n *html.Node) string {
+	var sb strings.Builder
+	scrapeText(n, &sb)
+	return strings.TrimSpace(spaces.ReplaceAllString(sb.String(), " "))
+}
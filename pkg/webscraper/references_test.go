@@ -0,0 +1,61 @@
+package webscraper
+
+import (
+	"testing"
+)
+
+// TestReferences verifies that archive links, access dates and dead URLs are read from rendered citation templates.
+func TestReferences(t *testing.T) {
+	page := `<html><body>
+		<p>Text.<sup><a href="#cite_note-1">[1]</a></sup></p>
+		<div class="reflist"><ol class="references">
+			<li id="cite_note-1"><span class="mw-cite-backlink"><a href="#cite_ref-1">^</a></span>
+				<span class="reference-text"><cite class="citation web cs1"><a href="/wiki/Jane_Doe">Doe, Jane</a>.
+				<a class="external text" href="https://web.archive.org/web/20190510120000/https://news.example.com/story">"A story"</a>.
+				Archived from <a class="external text" href="https://news.example.com/story">the original</a> on 10 May 2019.
+				Retrieved <span class="reference-accessdate">12 March 2020</span>.</cite></span></li>
+			<li id="cite_note-2"><span class="reference-text"><cite class="citation web cs1">
+				<a class="external text" href="https://example.org/live">"Live page"</a>.
+				<a class="external text" href="https://web.archive.org/web/20180101000000/https://example.org/live">Archived</a> from the original on 1 January 2018.
+				Retrieved <span class="reference-accessdate">2018-01-02</span>.</cite></span></li>
+			<li id="cite_note-3"><span class="reference-text"><cite class="citation web cs1">"Usurped page".
+				<a class="external text" href="https://web.archive.org/web/20150101000000/http://usurped.example.net/">Archived</a> from the original on 1 January 2015.</cite></span></li>
+			<li id="cite_note-4">Smith, John (2001). <i>A Book Without a Link</i>. Accessed on June 3, 2019.</li>
+			<li id="cite_note-5">"World Values Survey 2020". https://example.net/survey</li>
+		</ol></div>
+	</body></html>`
+
+	references, err := References(page, "https://en.example.org/wiki/Article")
+	if err != nil {
+		t.Fatalf("Error reading references: %v", err)
+	}
+	want := []Reference{
+		{Index: 1, ID: "cite_note-1", URL: "https://news.example.com/story", ArchiveURL: "https://web.archive.org/web/20190510120000/https://news.example.com/story", Dead: true},
+		{Index: 2, ID: "cite_note-2", URL: "https://example.org/live", ArchiveURL: "https://web.archive.org/web/20180101000000/https://example.org/live"},
+		{Index: 3, ID: "cite_note-3", URL: "http://usurped.example.net/", ArchiveURL: "https://web.archive.org/web/20150101000000/http://usurped.example.net/", Dead: true},
+		{Index: 4, ID: "cite_note-4"},
+		{Index: 5, ID: "cite_note-5", URL: "https://example.net/survey"},
+	}
+	wantAccess := []string{"2020-03-12", "2018-01-02", "", "2019-06-03", ""}
+	if len(references) != len(want) {
+		t.Fatalf("Expected %d references, got %+v", len(want), references)
+	}
+	for i, reference := range references {
+		if reference.Index != want[i].Index || reference.ID != want[i].ID || reference.URL != want[i].URL || reference.ArchiveURL != want[i].ArchiveURL || reference.Dead != want[i].Dead {
+			t.Errorf("Expected reference %d to be %+v, got %+v", i+1, want[i], reference)
+		}
+		access := ""
+		if !reference.AccessDate.IsZero() {
+			access = reference.AccessDate.Format("2006-01-02")
+		}
+		if access != wantAccess[i] {
+			t.Errorf("Expected reference %d to be accessed on %q, got %q", i+1, wantAccess[i], access)
+		}
+	}
+	if len(references[3].Italics) != 1 || references[3].Italics[0] != "A Book Without a Link" {
+		t.Errorf("Expected the italicized title of reference 4, got %q", references[3].Italics)
+	}
+	if references[0].Text[0] == '^' {
+		t.Errorf("Expected the backlink to be left out of the text, got %q", references[0].Text)
+	}
+}
@@ -2,7 +2,9 @@ package webscraper
 
 import (
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"golang.org/x/net/html"
@@ -47,6 +49,15 @@ func scrapeText(n *html.Node, sb *strings.Builder) {
 
 // ScrapeBody fetches only the text content within the <body> tag of a webpage.
 func ScrapeBody(url string) (string, error) {
+	page, err := FetchHTML(url)
+	if err != nil {
+		return "", err
+	}
+	return BodyText(page)
+}
+
+// FetchHTML fetches a webpage and returns its raw HTML.
+func FetchHTML(url string) (string, error) {
 	// Make a GET request to the URL
 	resp, err := http.Get(url)
 	if err != nil {
@@ -58,8 +69,17 @@ func ScrapeBody(url string) (string, error) {
 		return "", fmt.Errorf("unexpected HTTP status: %s", resp.Status)
 	}
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the page: %v", err)
+	}
+	return string(body), nil
+}
+
+// BodyText extracts the text content within the <body> tag of an HTML document.
+func BodyText(page string) (string, error) {
 	// Parse the HTML document
-	doc, err := html.Parse(resp.Body)
+	doc, err := html.Parse(strings.NewReader(page))
 	if err != nil {
 		return "", fmt.Errorf("failed to parse the page HTML: %v", err)
 	}
@@ -89,3 +109,42 @@ func findBodyNode(n *html.Node) *html.Node {
 	}
 	return nil
 }
+
+// Links returns the absolute http and https links of the <a> tags within the <body> tag of an HTML
+// document, resolved against the page URL, without fragments or duplicates, in the order they appear.
+func Links(page, pageURL string) ([]string, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page URL: %v", err)
+	}
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the page HTML: %v", err)
+	}
+	bodyNode := findBodyNode(doc)
+	if bodyNode == nil {
+		return nil, fmt.Errorf("no <body> tag found in the page")
+	}
+
+	links := []string{}
+	seen := make(map[string]bool)
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			if href, ok := hasAttribute(n, "href"); ok {
+				if link, err := base.Parse(strings.TrimSpace(href)); err == nil && (link.Scheme == "http" || link.Scheme == "https") {
+					link.Fragment = ""
+					if !seen[link.String()] {
+						seen[link.String()] = true
+						links = append(links, link.String())
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(bodyNode)
+	return links, nil
+}
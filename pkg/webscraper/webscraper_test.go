@@ -81,3 +81,36 @@ func TestScrapeRealPage(t *testing.T) {
 		t.Errorf("expected non-empty result, got empty string")
 	}
 }
+
+// TestBodyText verifies that only the text inside the <body> tag is extracted.
+func TestBodyText(t *testing.T) {
+	result, err := BodyText(`<html><head><title>Ignored Title</title></head><body><p>Body text.</p></body></html>`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(result, "Body text.") || strings.Contains(result, "Ignored Title") {
+		t.Errorf("expected only body text, got '%s'", result)
+	}
+}
+
+// TestLinks verifies that links are resolved against the page URL, deduplicated and stripped of fragments.
+func TestLinks(t *testing.T) {
+	page := `<html><head><link href="https://cdn.example.org/style.css"></head><body>
+		<a href="/wiki/Other">Other</a> <a href="#top">Top</a> <a href="https://example.net/a#b">A</a>
+		<a href="https://example.net/a">A again</a> <a href="mailto:someone@example.org">Mail</a> <a>No link</a>
+	</body></html>`
+
+	links, err := Links(page, "https://example.org/wiki/Trust")
+	if err != nil {
+		t.Fatalf("Error extracting links: %v", err)
+	}
+	want := []string{"https://example.org/wiki/Other", "https://example.org/wiki/Trust", "https://example.net/a"}
+	if len(links) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, links)
+	}
+	for i := range want {
+		if links[i] != want[i] {
+			t.Errorf("Expected link %d to be %s, got %s", i, want[i], links[i])
+		}
+	}
+}